@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/prompt"
+	"github.com/rayprogramming/copilot-os/internal/server"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// errAgentsFailed is returned by run when an agent in the chain failed, so
+// the command exits non-zero.
+var errAgentsFailed = errors.New("one or more agents failed")
+
+// commandFlags holds the flags shared by the offline commands.
+type commandFlags struct {
+	fs     *flag.FlagSet
+	repo   *string
+	asJSON *bool
+	args   []string // Positional arguments, set by parse
+}
+
+// newCommandFlags creates a flag set with the --json flag and, when withRepo
+// is set, the --repo flag.
+func newCommandFlags(name string, withRepo bool) *commandFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	f := &commandFlags{
+		fs:     fs,
+		asJSON: fs.Bool("json", false, "print JSON instead of text"),
+	}
+	if withRepo {
+		f.repo = fs.String("repo", "", "repository to use, by name; defaults to the first repository")
+	}
+	return f
+}
+
+// parse parses args into the flags and the positional arguments. Unlike
+// flag.FlagSet.Parse, flags may also follow positional arguments, so
+// `run "Review auth.go" --json` prints JSON; arguments after "--" are all
+// positional.
+func (f *commandFlags) parse(args []string) error {
+	for {
+		if err := f.fs.Parse(args); err != nil {
+			return err
+		}
+		rest := f.fs.Args()
+		if len(rest) == 0 {
+			return nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			f.args = append(f.args, rest...)
+			return nil
+		}
+		f.args = append(f.args, rest[0])
+		args = rest[1:]
+	}
+}
+
+// promptArg joins the positional arguments into the prompt.
+func (f *commandFlags) promptArg() (string, error) {
+	p := strings.TrimSpace(strings.Join(f.args, " "))
+	if p == "" {
+		return "", fmt.Errorf("%s: a prompt is required", f.fs.Name())
+	}
+	return p, nil
+}
+
+// openRepo discovers the configured repositories and returns the one named
+// by --repo. Agents are always run with the Copilot CLI, as there is no MCP
+// client to sample from.
+func (f *commandFlags) openRepo() (*workspace.Repo, error) {
+	cfg := config.LoadFromEnv()
+	logger, err := commandLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := loadWorkspace(cfg, cli.NewInvoker(cfg.CLITimeout, logger), logger)
+	if err != nil {
+		return nil, err
+	}
+	return ws.Get(*f.repo)
+}
+
+// commandLogger builds the logger of the offline commands. Only warnings and
+// errors are logged unless LOG_LEVEL is set explicitly, so output on the
+// terminal stays readable.
+func commandLogger(cfg *config.Config) (*zap.Logger, error) {
+	level := "warn"
+	if os.Getenv("LOG_LEVEL") != "" {
+		level = cfg.LogLevel
+	}
+	return newLogger(level)
+}
+
+// runCommand orchestrates a prompt, or runs the agents given with --chain in
+// order, and prints the resulting ContextState. Interrupting the command
+// cancels the chain and prints the partial state.
+func runCommand(args []string) error {
+	f := newCommandFlags("run", true)
+	chain := f.fs.String("chain", "", "comma-separated agents to run in order instead of selecting agents")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	userPrompt, err := f.promptArg()
+	if err != nil {
+		return err
+	}
+	repo, err := f.openRepo()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var state *orchestrator.ContextState
+	if names := splitList(*chain); len(names) > 0 {
+		state, err = repo.Orchestrator.RunWithExplicitChain(ctx, userPrompt, names)
+	} else {
+		state, err = repo.Orchestrator.RunWithAuto(ctx, userPrompt)
+	}
+
+	if werr := writeOutput(*f.asJSON, state, writeState); werr != nil {
+		return werr
+	}
+	if err != nil {
+		return err
+	}
+	for _, result := range state.AgentResults {
+		if !result.Success {
+			return errAgentsFailed
+		}
+	}
+	return nil
+}
+
+// planCommand prints the ContextState run would start with: the evaluated
+// prompt and the selected agents, without running them.
+func planCommand(args []string) error {
+	f := newCommandFlags("plan", true)
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	userPrompt, err := f.promptArg()
+	if err != nil {
+		return err
+	}
+	repo, err := f.openRepo()
+	if err != nil {
+		return err
+	}
+
+	state, err := repo.Orchestrator.Plan(context.Background(), userPrompt)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*f.asJSON, state, writeState)
+}
+
+// evaluateCommand prints the EvaluationResult of a prompt.
+func evaluateCommand(args []string) error {
+	f := newCommandFlags("evaluate", false)
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	userPrompt, err := f.promptArg()
+	if err != nil {
+		return err
+	}
+
+	evaluation := prompt.NewEvaluator().Evaluate(userPrompt)
+	return writeOutput(*f.asJSON, &evaluation, writeEvaluation)
+}
+
+// agentDetail is the JSON output of agents show.
+type agentDetail struct {
+	*agents.Agent
+	Repo string `json:"repo"`
+}
+
+// agentsCommand lists the agents of a repository (agents list), prints one
+// agent with its instructions (agents show <name>) or checks the agent files
+// (agents lint).
+func agentsCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("agents: expected list, show or lint")
+	}
+	sub, args := args[0], args[1:]
+	if sub == "lint" {
+		return lintCommand(args)
+	}
+
+	f := newCommandFlags("agents "+sub, true)
+	var namespace *string
+	if sub == "list" {
+		namespace = f.fs.String("namespace", "", "only list agents of this namespace and the namespaces nested in it")
+	}
+	if err := f.parse(args); err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		repo, err := f.openRepo()
+		if err != nil {
+			return err
+		}
+		all := repo.Registry.InNamespace(*namespace)
+		return writeOutput(*f.asJSON, &server.ListAgentsOutput{
+			Repo:   repo.Name,
+			Agents: all,
+			Count:  len(all),
+		}, writeAgentList)
+	case "show":
+		if len(f.args) != 1 {
+			return errors.New("agents show: expected one agent name")
+		}
+		repo, err := f.openRepo()
+		if err != nil {
+			return err
+		}
+		agent, err := repo.Registry.Lookup(f.args[0])
+		if err != nil {
+			return err
+		}
+		return writeOutput(*f.asJSON, &agentDetail{Agent: agent, Repo: repo.Name}, writeAgent)
+	default:
+		return fmt.Errorf("agents: unknown subcommand %q (expected list, show or lint)", sub)
+	}
+}
+
+// writeOutput prints v to stdout as indented JSON, or as text with write.
+func writeOutput[T any](asJSON bool, v *T, write func(io.Writer, *T) error) error {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return write(os.Stdout, v)
+}
+
+// writeState prints a ContextState: the prompt, its evaluation, the selected
+// agents and status, then the synthesized output of a run.
+//
+// Output Layout:
+//
+//	Prompt:      Review auth.go
+//	Refined:     Review auth.go for security issues
+//	Confidence:  0.80
+//	Agents:      code-reviewer, test-generator
+//	Rationale:   Selected based on keywords: review. Agents: code-reviewer
+//	Status:      completed
+//
+//	=== Agent Chain Results ===
+//	...
+func writeState(w io.Writer, state *orchestrator.ContextState) error {
+	var b strings.Builder
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Prompt:\t%s\n", state.OriginalPrompt)
+	if state.RefinedPrompt != "" && state.RefinedPrompt != state.OriginalPrompt {
+		fmt.Fprintf(tw, "Refined:\t%s\n", state.RefinedPrompt)
+	}
+	fmt.Fprintf(tw, "Confidence:\t%.2f\n", state.EvaluationFeedback.Confidence)
+	if issues := state.EvaluationFeedback.DetectedIssues; len(issues) > 0 {
+		fmt.Fprintf(tw, "Issues:\t%s\n", strings.Join(issues, "; "))
+	}
+	fmt.Fprintf(tw, "Agents:\t%s\n", strings.Join(state.SelectedAgents, ", "))
+	if state.SelectionRationale != "" {
+		fmt.Fprintf(tw, "Rationale:\t%s\n", state.SelectionRationale)
+	}
+	fmt.Fprintf(tw, "Status:\t%s\n", state.Status)
+	if len(state.AbortedAgents) > 0 {
+		fmt.Fprintf(tw, "Aborted:\t%s\n", strings.Join(state.AbortedAgents, ", "))
+	}
+	tw.Flush()
+
+	if state.FinalOutput != "" {
+		fmt.Fprintf(&b, "\n%s", state.FinalOutput)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeEvaluation prints an EvaluationResult.
+func writeEvaluation(w io.Writer, evaluation *prompt.EvaluationResult) error {
+	var b strings.Builder
+
+	clearText := "no"
+	if evaluation.IsClear {
+		clearText = "yes"
+	}
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Clear:\t%s (confidence %.2f)\n", clearText, evaluation.Confidence)
+	fmt.Fprintf(tw, "Feedback:\t%s\n", evaluation.Feedback)
+	for _, issue := range evaluation.DetectedIssues {
+		fmt.Fprintf(tw, "Issue:\t%s\n", issue)
+	}
+	if evaluation.SuggestedRefinement != "" {
+		fmt.Fprintf(tw, "Suggestion:\t%s\n", evaluation.SuggestedRefinement)
+	}
+	fmt.Fprintf(tw, "Refined:\t%s\n", evaluation.RefinedPrompt)
+	if len(evaluation.SuggestedAgentKeywords) > 0 {
+		fmt.Fprintf(tw, "Keywords:\t%s\n", strings.Join(evaluation.SuggestedAgentKeywords, ", "))
+	}
+	tw.Flush()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeAgentList prints one line per agent with its description and keywords.
+func writeAgentList(w io.Writer, list *server.ListAgentsOutput) error {
+	var b strings.Builder
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDESCRIPTION\tKEYWORDS")
+	for _, agent := range list.Agents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", agent.Name, agent.Description, strings.Join(agent.Keywords, ", "))
+	}
+	tw.Flush()
+	fmt.Fprintf(&b, "\n%d agents in %s\n", list.Count, list.Repo)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeAgent prints an agent's metadata followed by its instructions.
+func writeAgent(w io.Writer, agent *agentDetail) error {
+	var b strings.Builder
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", agent.Name)
+	fmt.Fprintf(tw, "Repository:\t%s\n", agent.Repo)
+	fmt.Fprintf(tw, "Description:\t%s\n", agent.Description)
+	if agent.Extends != "" {
+		fmt.Fprintf(tw, "Extends:\t%s\n", agent.Extends)
+	}
+	fmt.Fprintf(tw, "Keywords:\t%s\n", strings.Join(agent.Keywords, ", "))
+	if len(agent.Tools) > 0 {
+		fmt.Fprintf(tw, "Tools:\t%s\n", strings.Join(agent.Tools, ", "))
+	}
+	if len(agent.Arguments) > 0 {
+		fmt.Fprintf(tw, "Arguments:\t%s\n", strings.Join(agent.Arguments, ", "))
+	}
+	if len(agent.Requires) > 0 {
+		fmt.Fprintf(tw, "Requires:\t%s\n", strings.Join(agent.Requires, ", "))
+	}
+	if len(agent.After) > 0 {
+		fmt.Fprintf(tw, "After:\t%s\n", strings.Join(agent.After, ", "))
+	}
+	if len(agent.Before) > 0 {
+		fmt.Fprintf(tw, "Before:\t%s\n", strings.Join(agent.Before, ", "))
+	}
+	fmt.Fprintf(tw, "Path:\t%s\n", agent.Path)
+	if agent.Format != "" {
+		fmt.Fprintf(tw, "Format:\t%s (%s)\n", agent.Format, agent.Source)
+	}
+	tw.Flush()
+
+	if agent.Instructions != "" {
+		fmt.Fprintf(&b, "\n%s\n", agent.Instructions)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/prompt"
+	"github.com/rayprogramming/copilot-os/internal/server"
+)
+
+// useTestRepo writes a repository with a code-reviewer and a test-generator
+// agent and configures the commands to serve only it.
+func useTestRepo(t *testing.T) string {
+	t.Helper()
+
+	root := filepath.Join(t.TempDir(), "payments")
+	dir := filepath.Join(root, ".github", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"code-reviewer.md":  "---\nname: code-reviewer\ndescription: Reviews code quality\nkeywords: [review, quality]\n---\nReview the code.\n",
+		"test-generator.md": "---\nname: test-generator\ndescription: Generates tests\nkeywords: [test, coverage]\n---\nWrite tests.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("REPO_ROOTS", root)
+	t.Setenv("AGENT_USER_DIR", t.TempDir())
+	t.Setenv("AGENT_PATHS", "")
+	t.Setenv("LOG_LEVEL", "")
+	return root
+}
+
+// captureStdout runs fn and returns what it printed to stdout.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	err = fn()
+	w.Close()
+	return <-out, err
+}
+
+// field returns the value printed after label in text output, or "" when
+// no line starts with label.
+func field(out, label string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if value, ok := strings.CutPrefix(line, label+":"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"code-reviewer", []string{"code-reviewer"}},
+		{"code-reviewer,test-generator", []string{"code-reviewer", "test-generator"}},
+		{" code-reviewer , ,test-generator, ", []string{"code-reviewer", "test-generator"}},
+		{",,", nil},
+	}
+
+	for _, tt := range tests {
+		if got := splitList(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRun_Arguments(t *testing.T) {
+	useTestRepo(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown command", []string{"deploy"}, `unknown command "deploy"`},
+		{"plan without prompt", []string{"plan"}, "plan: a prompt is required"},
+		{"plan with blank prompt", []string{"plan", " ", ""}, "plan: a prompt is required"},
+		{"run without prompt", []string{"run", "--chain", "code-reviewer"}, "run: a prompt is required"},
+		{"evaluate without prompt", []string{"evaluate", "--json"}, "evaluate: a prompt is required"},
+		{"unknown flag", []string{"plan", "--verbose", "Review"}, "flag provided but not defined: -verbose"},
+		{"unknown flag after prompt", []string{"plan", "Review", "the code", "--verbose"}, "flag provided but not defined: -verbose"},
+		{"evaluate has no repo flag", []string{"evaluate", "--repo", "payments", "Review"}, "flag provided but not defined: -repo"},
+		{"unknown repository", []string{"plan", "--repo", "search", "Review the code"}, "search"},
+		{"agents without subcommand", []string{"agents"}, "agents: expected list, show or lint"},
+		{"unknown agents subcommand", []string{"agents", "delete"}, `agents: unknown subcommand "delete"`},
+		{"agents show without name", []string{"agents", "show"}, "agents show: expected one agent name"},
+		{"agents show with two names", []string{"agents", "show", "code-reviewer", "test-generator"}, "agents show: expected one agent name"},
+		{"agents list has no chain flag", []string{"agents", "list", "--chain", "code-reviewer"}, "flag provided but not defined: -chain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := captureStdout(t, func() error { return run(tt.args) })
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("run(%q) error = %v, want it to contain %q", tt.args, err, tt.want)
+			}
+			if out != "" {
+				t.Errorf("expected no output on stdout, got %q", out)
+			}
+		})
+	}
+}
+
+func TestPlanCommand(t *testing.T) {
+	useTestRepo(t)
+	const userPrompt = "Review the payment code for quality issues"
+
+	t.Run("text", func(t *testing.T) {
+		out, err := captureStdout(t, func() error { return run([]string{"plan", userPrompt}) })
+		if err != nil {
+			t.Fatalf("plan failed: %v", err)
+		}
+		for label, want := range map[string]string{
+			"Prompt": userPrompt,
+			"Agents": "code-reviewer",
+			"Status": "planned",
+		} {
+			if got := field(out, label); got != want {
+				t.Errorf("%s = %q, want %q in:\n%s", label, got, want, out)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			// Flags may follow the prompt.
+			return run([]string{"plan", "Review", "the", "payment", "code", "--repo", "payments", "for", "quality", "issues", "--json"})
+		})
+		if err != nil {
+			t.Fatalf("plan failed: %v", err)
+		}
+		var state orchestrator.ContextState
+		if err := json.Unmarshal([]byte(out), &state); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		if state.OriginalPrompt != userPrompt {
+			t.Errorf("original prompt = %q, want %q", state.OriginalPrompt, userPrompt)
+		}
+		if !slices.Equal(state.SelectedAgents, []string{"code-reviewer"}) {
+			t.Errorf("selected agents = %q, want [code-reviewer]", state.SelectedAgents)
+		}
+		if state.Status != orchestrator.StatusPlanned {
+			t.Errorf("status = %q, want %q", state.Status, orchestrator.StatusPlanned)
+		}
+		if len(state.AgentResults) != 0 {
+			t.Errorf("expected no agent results, got %d", len(state.AgentResults))
+		}
+	})
+}
+
+func TestCommandFlags_Parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		json   bool
+		repo   string
+		prompt string
+	}{
+		{"flags first", []string{"--json", "--repo", "payments", "Review", "auth.go"}, true, "payments", "Review auth.go"},
+		{"flags last", []string{"Review auth.go", "--json"}, true, "", "Review auth.go"},
+		{"flags between", []string{"Review", "--repo=payments", "auth.go"}, false, "payments", "Review auth.go"},
+		{"after --", []string{"--json", "--", "--repo", "is", "a flag"}, true, "", "--repo is a flag"},
+		{"-- after the prompt", []string{"Explain", "--", "-v"}, false, "", "Explain -v"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCommandFlags("plan", true)
+			if err := f.parse(tt.args); err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			userPrompt, err := f.promptArg()
+			if err != nil {
+				t.Fatalf("promptArg failed: %v", err)
+			}
+			if *f.asJSON != tt.json || *f.repo != tt.repo || userPrompt != tt.prompt {
+				t.Errorf("got json=%v repo=%q prompt=%q, want json=%v repo=%q prompt=%q",
+					*f.asJSON, *f.repo, userPrompt, tt.json, tt.repo, tt.prompt)
+			}
+		})
+	}
+}
+
+func TestEvaluateCommand(t *testing.T) {
+	const userPrompt = "Generate unit tests for the payment service"
+	want := prompt.NewEvaluator().Evaluate(userPrompt)
+
+	t.Run("text", func(t *testing.T) {
+		out, err := captureStdout(t, func() error { return run([]string{"evaluate", userPrompt}) })
+		if err != nil {
+			t.Fatalf("evaluate failed: %v", err)
+		}
+		for label, value := range map[string]string{
+			"Feedback": want.Feedback,
+			"Refined":  want.RefinedPrompt,
+		} {
+			if got := field(out, label); got != value {
+				t.Errorf("%s = %q, want %q in:\n%s", label, got, value, out)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := captureStdout(t, func() error { return run([]string{"evaluate", "--json", userPrompt}) })
+		if err != nil {
+			t.Fatalf("evaluate failed: %v", err)
+		}
+		var got prompt.EvaluationResult
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		if got.IsClear != want.IsClear || got.Confidence != want.Confidence || got.RefinedPrompt != want.RefinedPrompt {
+			t.Errorf("evaluation = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestAgentsCommand(t *testing.T) {
+	root := useTestRepo(t)
+
+	t.Run("list", func(t *testing.T) {
+		out, err := captureStdout(t, func() error { return run([]string{"agents", "list", "--json"}) })
+		if err != nil {
+			t.Fatalf("agents list failed: %v", err)
+		}
+		var list server.ListAgentsOutput
+		if err := json.Unmarshal([]byte(out), &list); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		if list.Repo != "payments" || list.Count != 2 {
+			t.Errorf("got %d agents in %q, want 2 in payments", list.Count, list.Repo)
+		}
+	})
+
+	t.Run("show", func(t *testing.T) {
+		out, err := captureStdout(t, func() error { return run([]string{"agents", "show", "code-reviewer"}) })
+		if err != nil {
+			t.Fatalf("agents show failed: %v", err)
+		}
+		for label, want := range map[string]string{
+			"Name":       "code-reviewer",
+			"Repository": "payments",
+			"Path":       filepath.Join(root, ".github", "agents", "code-reviewer.md"),
+		} {
+			if got := field(out, label); got != want {
+				t.Errorf("%s = %q, want %q in:\n%s", label, got, want, out)
+			}
+		}
+		if !strings.HasSuffix(out, "\n\nReview the code.\n") {
+			t.Errorf("expected output to end with the instructions, got:\n%s", out)
+		}
+	})
+
+	t.Run("show unknown agent", func(t *testing.T) {
+		_, err := captureStdout(t, func() error { return run([]string{"agents", "show", "doc-writer"}) })
+		if err == nil || !strings.Contains(err.Error(), "doc-writer") {
+			t.Fatalf("expected an error naming doc-writer, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+//go:build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+)
+
+// installFakeCopilot puts an executable named copilot on PATH that runs script.
+func installFakeCopilot(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "copilot")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake copilot: %v", err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestRunCommand(t *testing.T) {
+	useTestRepo(t)
+	installFakeCopilot(t, `echo "reviewed by ${1#--agent=}"`)
+
+	t.Run("text", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return run([]string{"run", "Review the payment code for quality issues"})
+		})
+		if err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		if got := field(out, "Agents"); got != "code-reviewer" {
+			t.Errorf("Agents = %q, want code-reviewer in:\n%s", got, out)
+		}
+		if got := field(out, "Status"); got != "completed" {
+			t.Errorf("Status = %q, want completed in:\n%s", got, out)
+		}
+		if !strings.Contains(out, "reviewed by code-reviewer") {
+			t.Errorf("expected output to contain the agent's output, got:\n%s", out)
+		}
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return run([]string{"run", "--json", "--chain", "test-generator, code-reviewer", "Check the payment code"})
+		})
+		if err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		var state orchestrator.ContextState
+		if err := json.Unmarshal([]byte(out), &state); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		if len(state.AgentResults) != 2 || state.AgentResults[0].Agent != "test-generator" || state.AgentResults[1].Agent != "code-reviewer" {
+			t.Errorf("expected test-generator then code-reviewer to run, got %+v", state.AgentResults)
+		}
+		if state.Status != orchestrator.StatusCompleted {
+			t.Errorf("status = %q, want %q", state.Status, orchestrator.StatusCompleted)
+		}
+	})
+}
+
+func TestRunCommand_FailedAgent(t *testing.T) {
+	useTestRepo(t)
+	installFakeCopilot(t, "echo 'model unavailable' >&2; exit 1")
+
+	// The partial state is still printed before the command fails.
+	out, err := captureStdout(t, func() error {
+		return run([]string{"run", "--json", "--chain", "code-reviewer", "Review the payment code"})
+	})
+	if !errors.Is(err, errAgentsFailed) {
+		t.Fatalf("expected errAgentsFailed, got %v", err)
+	}
+	var state orchestrator.ContextState
+	if err := json.Unmarshal([]byte(out), &state); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(state.AgentResults) != 1 || state.AgentResults[0].Success {
+		t.Errorf("expected one failed agent result, got %+v", state.AgentResults)
+	}
+}
+
+func TestMain_ExitCode(t *testing.T) {
+	if os.Getenv("COPILOT_OS_TEST_MAIN") == "1" {
+		os.Args = append([]string{"copilot-os"}, strings.Fields(os.Getenv("COPILOT_OS_TEST_ARGS"))...)
+		main()
+		os.Exit(0)
+	}
+
+	useTestRepo(t)
+	installFakeCopilot(t, "exit 1")
+
+	tests := []struct {
+		name string
+		args string
+		want int
+	}{
+		{"evaluate", "evaluate Review the payment code", 0},
+		{"missing prompt", "plan", 1},
+		{"failed agent", "run --chain code-reviewer Review the payment code", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestMain_ExitCode$")
+			cmd.Env = append(os.Environ(), "COPILOT_OS_TEST_MAIN=1", "COPILOT_OS_TEST_ARGS="+tt.args)
+			err := cmd.Run()
+
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("failed to run command: %v", err)
+			}
+			if code != tt.want {
+				t.Errorf("copilot-os %s exited with %d, want %d", tt.args, code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/diagnostics"
+	"github.com/rayprogramming/copilot-os/internal/server"
+	"go.uber.org/zap"
+)
+
+// errChecksFailed is returned by doctor when a check fails, so the command
+// exits non-zero.
+var errChecksFailed = errors.New("diagnostics failed")
+
+// doctor runs the diagnostics checks against the configured repositories and
+// prints the report as text, or as JSON with --json. With quiet, only the
+// overall status is printed, which suits container health checks.
+func doctor(args []string, quiet bool) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.LoadFromEnv()
+	backend, ok := server.ParseBackend(cfg.Backend)
+	if !ok {
+		return fmt.Errorf("unsupported AGENT_BACKEND %q (expected cli, sampling or auto)", cfg.Backend)
+	}
+
+	// Discovery problems are part of the report, so logging is not needed.
+	logger := zap.NewNop()
+	invoker := cli.NewInvoker(cfg.CLITimeout, logger)
+	ws, err := loadWorkspace(cfg, invoker, logger)
+	if err != nil {
+		return err
+	}
+
+	report := diagnostics.Run(context.Background(), diagnostics.Options{
+		Build:       diagnostics.BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime},
+		Config:      cfg,
+		CLI:         invoker,
+		CLIRequired: backend == server.BackendCLI,
+		Repos:       ws.All(),
+	})
+
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case quiet:
+		_, err = fmt.Println(report.Status)
+	default:
+		err = writeReport(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Status == diagnostics.StatusFail {
+		return errChecksFailed
+	}
+	return nil
+}
+
+// statusSymbols marks each check status in the text report.
+var statusSymbols = map[diagnostics.Status]string{
+	diagnostics.StatusPass: "✓",
+	diagnostics.StatusWarn: "!",
+	diagnostics.StatusFail: "✗",
+}
+
+// writeReport prints a human-readable report.
+//
+// Report Layout:
+//
+//	Status: warn
+//
+//	✓ copilot_cli    copilot 0.0.339
+//	! repo:app       2 agents, 1 files failed to parse
+//
+//	Repositories:
+//	  app  /src/app/.github/agents
+//	    agents: code-reviewer, test-generator
+//	    ✗ /src/app/.github/agents/bad.md: frontmatter not found
+//	    /org/agents/code-reviewer.md overridden by /src/app/.github/agents/code-reviewer.md
+//
+//	Configuration:
+//	  REPO_ROOT=.
+//	  ...
+//
+//	Build: dev (commit unknown, built unknown)
+func writeReport(w io.Writer, report *diagnostics.Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Status: %s\n\n", report.Status)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	for _, check := range report.Checks {
+		fmt.Fprintf(tw, "%s %s\t%s\n", statusSymbols[check.Status], check.Name, check.Message)
+	}
+	tw.Flush()
+
+	b.WriteString("\nRepositories:\n")
+	for _, repo := range report.Repos {
+		fmt.Fprintf(&b, "  %s  %s\n", repo.Name, repo.AgentsDir)
+		if len(repo.Agents) > 0 {
+			fmt.Fprintf(&b, "    agents: %s\n", strings.Join(repo.Agents, ", "))
+		}
+		for _, failure := range repo.Failures {
+			fmt.Fprintf(&b, "    %s %s: %s\n", statusSymbols[diagnostics.StatusFail], failure.File, failure.Error)
+		}
+		for _, override := range repo.Overrides {
+			fmt.Fprintf(&b, "    %s overridden by %s\n", override.Path, override.OverriddenBy)
+		}
+	}
+
+	b.WriteString("\nConfiguration:\n")
+	for _, setting := range report.Config {
+		fmt.Fprintf(&b, "  %s=%s\n", setting.Env, setting.Value)
+	}
+
+	fmt.Fprintf(&b, "\nBuild: %s (commit %s, built %s)\n", report.Build.Version, report.Build.Commit, report.Build.BuildTime)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
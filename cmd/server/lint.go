@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/server"
+	"go.uber.org/zap"
+)
+
+// errLintFailed is returned by agents lint when an agent file has errors, so
+// the command exits non-zero.
+var errLintFailed = errors.New("agent files have errors")
+
+// lintCommand checks the agent files of a repository and of the shared
+// search paths, printing the issues as text, JSON (--json or --format json)
+// or GitHub Actions annotations (--format github). Warnings alone do not
+// fail the command.
+func lintCommand(args []string) error {
+	f := newCommandFlags("agents lint", true)
+	format := f.fs.String("format", "text", "output format: text, json or github")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *f.asJSON {
+		*format = "json"
+	}
+
+	var write func(io.Writer, *server.LintAgentsOutput) error
+	switch *format {
+	case "text":
+		write = writeLintReport
+	case "json":
+	case "github":
+		write = writeLintAnnotations
+	default:
+		return fmt.Errorf("agents lint: unknown format %q (expected text, json or github)", *format)
+	}
+
+	// Discovery problems are part of the report, so logging is not needed.
+	cfg := config.LoadFromEnv()
+	logger := zap.NewNop()
+	ws, err := loadWorkspace(cfg, cli.NewInvoker(cfg.CLITimeout, logger), logger)
+	if err != nil {
+		return err
+	}
+	repo, err := ws.Get(*f.repo)
+	if err != nil {
+		return err
+	}
+	report, err := ws.Lint(repo)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutput(write == nil, &server.LintAgentsOutput{Repo: repo.Name, LintReport: *report}, write); err != nil {
+		return err
+	}
+	if report.Errors > 0 {
+		return errLintFailed
+	}
+	return nil
+}
+
+// writeLintReport prints one line per issue, with file paths relative to
+// the working directory, followed by a summary.
+//
+// Report Layout:
+//
+//	.github/agents/docs.md:3: warning: keywords "golang" are never extracted from a prompt, so they never match (unreachable-keyword)
+//	.github/agents/notes.md: error: no frontmatter found (invalid-file)
+//
+//	6 agent files in app: 1 errors, 1 warnings
+func writeLintReport(w io.Writer, out *server.LintAgentsOutput) error {
+	var b strings.Builder
+	for _, issue := range out.Issues {
+		location := relativePath(issue.File)
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, issue.Line)
+		}
+		fmt.Fprintf(&b, "%s: %s: %s (%s)\n", location, issue.Severity, issue.Message, issue.Rule)
+	}
+	if len(out.Issues) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d agent files in %s: %d errors, %d warnings\n", out.Files, out.Repo, out.Errors, out.Warnings)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeLintAnnotations prints each issue as a GitHub Actions workflow
+// command, so it is shown as an annotation on the agent file:
+//
+//	::warning file=.github/agents/docs.md,line=3,title=unreachable-keyword::keywords "golang" are never ...
+func writeLintAnnotations(w io.Writer, out *server.LintAgentsOutput) error {
+	var b strings.Builder
+	for _, issue := range out.Issues {
+		properties := "file=" + escapeProperty(relativePath(issue.File))
+		if issue.Line > 0 {
+			properties += fmt.Sprintf(",line=%d", issue.Line)
+		}
+		properties += ",title=" + escapeProperty(issue.Rule)
+		fmt.Fprintf(&b, "::%s %s::%s\n", issue.Severity, properties, escapeData(issue.Message))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeData escapes the message of a GitHub Actions workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a GitHub Actions workflow
+// command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// relativePath returns path relative to the working directory when it is
+// inside it, as GitHub annotations expect, and path unchanged otherwise.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,246 @@
+// Command server runs the CopilotOS MCP server.
+//
+// The server loads configuration from the environment, discovers agents in
+// the .github/agents/ directory of each configured repository and of the
+// roots reported by MCP clients, and serves the orchestrator tools over the
+// MCP stdio transport, or over streamable HTTP when --listen (or
+// MCP_TRANSPORT=http) is given. The run, plan, evaluate and agents commands use
+// the orchestrator directly, without an MCP client.
+//
+// Usage:
+//
+//	copilot-os [serve] [--listen addr]
+//	copilot-os run [--chain a,b] [--repo name] [--json] "<prompt>"
+//	copilot-os plan [--repo name] [--json] "<prompt>"
+//	copilot-os evaluate [--json] "<prompt>"
+//	copilot-os agents list [--repo name] [--json]
+//	copilot-os agents show [--repo name] [--json] <name>
+//	copilot-os agents lint [--repo name] [--json] [--format text|json|github]
+//	copilot-os schema [tool...]
+//	copilot-os doctor [--json]
+//	copilot-os version
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/server"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// Build information, populated via -ldflags at build time.
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	Commit    = "unknown"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "copilot-os: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches to the requested subcommand.
+func run(args []string) error {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		return serve(args)
+	case "schema":
+		return schema(args)
+	case "run":
+		return runCommand(args)
+	case "plan":
+		return planCommand(args)
+	case "evaluate":
+		return evaluateCommand(args)
+	case "agents":
+		return agentsCommand(args)
+	case "doctor":
+		return doctor(args, false)
+	case "health":
+		return doctor(args, true)
+	case "version", "--version", "-v":
+		fmt.Printf("copilot-os %s (commit %s, built %s)\n", Version, Commit, BuildTime)
+		return nil
+	case "help", "--help", "-h":
+		printUsage()
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// serve starts the MCP server over the configured transport.
+func serve(args []string) error {
+	cfg := config.LoadFromEnv()
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "", "serve streamable HTTP on this address instead of stdio")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *listen != "" {
+		cfg.Transport = "http"
+		cfg.ListenAddr = *listen
+	}
+
+	backend, ok := server.ParseBackend(cfg.Backend)
+	if !ok {
+		return fmt.Errorf("unsupported AGENT_BACKEND %q (expected cli, sampling or auto)", cfg.Backend)
+	}
+	roots, ok := server.ParseRootsMode(cfg.ClientRoots)
+	if !ok {
+		return fmt.Errorf("unsupported CLIENT_ROOTS %q (expected merge, replace or off)", cfg.ClientRoots)
+	}
+
+	logger, err := newLogger(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	defer logger.Sync() //nolint:errcheck
+
+	logger.Info("loaded configuration",
+		zap.Strings("repo_roots", cfg.RepoRoots),
+		zap.String("log_level", cfg.LogLevel),
+		zap.Duration("cli_timeout", cfg.CLITimeout),
+		zap.String("transport", cfg.Transport),
+		zap.String("backend", string(backend)),
+		zap.String("client_roots", string(roots)),
+		zap.Duration("watch_interval", cfg.WatchInterval),
+	)
+
+	invoker := cli.NewInvoker(cfg.CLITimeout, logger)
+	ws, err := loadWorkspace(cfg, invoker, logger)
+	if err != nil {
+		return err
+	}
+
+	jobManager := jobs.NewManager(ws, jobs.Options{
+		MaxConcurrent: cfg.MaxConcurrentJobs,
+		Retention:     cfg.JobRetention,
+	}, logger)
+	defer jobManager.Close()
+
+	srv := server.New(ws, invoker, jobManager, server.Options{
+		Info: server.Info{
+			Version:   Version,
+			BuildTime: BuildTime,
+			Commit:    Commit,
+		},
+		Backend: backend,
+		Roots:   roots,
+		Config:  cfg,
+	}, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.WatchInterval > 0 {
+		go ws.Watch(ctx, cfg.WatchInterval, func(*workspace.Repo, agents.Diff) {
+			srv.SyncAgents()
+		})
+	}
+
+	switch cfg.Transport {
+	case "stdio":
+		err = srv.Run(ctx)
+	case "http":
+		err = srv.RunHTTP(ctx, cfg.ListenAddr, cfg.SessionTimeout)
+	default:
+		return fmt.Errorf("unsupported MCP_TRANSPORT %q (expected stdio or http)", cfg.Transport)
+	}
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+
+	logger.Info("MCP server stopped")
+	return nil
+}
+
+// loadWorkspace discovers the agents of every configured repository, each
+// together with the user's and the shared agent directories.
+func loadWorkspace(cfg *config.Config, invoker orchestrator.Invoker, logger *zap.Logger) (*workspace.Workspace, error) {
+	ws := workspace.New(invoker, logger)
+	ws.SetSearchPaths(agentSearchPaths(cfg))
+	for _, root := range cfg.RepoRoots {
+		if _, err := ws.Add(root); err != nil {
+			return nil, err
+		}
+	}
+	return ws, nil
+}
+
+// agentSearchPaths returns the agent directories searched after each
+// repository's own: the user's agents directory, then AGENT_PATHS in order.
+func agentSearchPaths(cfg *config.Config) []agents.SearchPath {
+	var paths []agents.SearchPath
+	if cfg.UserAgentsDir != "" {
+		paths = append(paths, agents.SearchPath{Dir: cfg.UserAgentsDir, Source: agents.SourceUser})
+	}
+	for _, dir := range cfg.AgentPaths {
+		paths = append(paths, agents.SearchPath{Dir: dir, Source: agents.SourceShared})
+	}
+	return paths
+}
+
+// newLogger builds a zap logger writing to stderr at the given level.
+// Stdout is reserved for MCP protocol messages.
+func newLogger(level string) (*zap.Logger, error) {
+	lvl, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", level, err)
+	}
+
+	zcfg := zap.NewProductionConfig()
+	zcfg.Level = lvl
+	zcfg.OutputPaths = []string{"stderr"}
+	zcfg.ErrorOutputPaths = []string{"stderr"}
+	return zcfg.Build()
+}
+
+// printUsage prints command usage to stderr.
+func printUsage() {
+	fmt.Fprint(os.Stderr, `Usage: copilot-os [command]
+
+Commands:
+  serve     Run the MCP server (default)
+            --listen addr  Serve streamable HTTP at addr/mcp instead of stdio
+  run       Orchestrate a prompt with the Copilot CLI and print the result
+            --chain a,b    Run these agents in order instead of selecting them
+  plan      Show the agents run would select, without running them
+  evaluate  Evaluate a prompt's clarity
+  agents    List discovered agents (agents list) or show one (agents show name)
+            agents lint checks agent files; exits non-zero on errors
+            --repo name    Use this repository instead of the first one
+            --json         Print JSON instead of text (run, plan, evaluate, agents)
+            --format f     agents lint output: text, json or github annotations
+            Flags may also follow the prompt; arguments after -- are the prompt
+  schema    Print MCP tool definitions with input/output JSON Schemas
+            [tool...]      Only print the named tools
+  doctor    Check the Copilot CLI, configuration and agent discovery
+            --json         Print the report as JSON
+  health    Print only the doctor status; exits non-zero on failure
+  version   Print build information
+  help      Show this help
+`)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/server"
+)
+
+// schema prints the MCP tool definitions, including their input and output
+// JSON Schemas, as JSON on stdout. With tool names as arguments, only those
+// tools are printed.
+func schema(args []string) error {
+	tools := server.Tools()
+
+	if len(args) > 0 {
+		byName := make(map[string]*mcp.Tool)
+		for _, tool := range tools {
+			byName[tool.Name] = tool
+		}
+
+		selected := make([]*mcp.Tool, 0, len(args))
+		for _, name := range args {
+			tool, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("unknown tool %q", name)
+			}
+			selected = append(selected, tool)
+		}
+		tools = selected
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tools)
+}
@@ -0,0 +1,177 @@
+package agents
+
+import "fmt"
+
+// Resolve resolves selected with the dependencies declared in the current
+// snapshot; see Snapshot.Resolve.
+func (r *Registry) Resolve(selected []*Agent) ([]*Agent, error) {
+	return r.Snapshot().Resolve(selected)
+}
+
+// Resolve returns the chain that runs selected with their dependencies.
+//
+// Agents named in requires are added to the chain, recursively, and run
+// before the agents requiring them. The chain is then ordered so that every
+// agent runs after the agents it requires or lists in after, and before the
+// agents it lists in before. after and before only order agents that are
+// already in the chain. Agents without constraints between them keep the
+// order of selected, with required agents placed just before their first
+// requirer.
+//
+// Names in these declarations are looked up in the declaring agent's
+// namespace first, then as by Lookup, so agents of a namespace can refer to
+// each other by their short names.
+//
+// Resolve returns a *NotFoundError or an error wrapping ErrAgentDisabled when
+// a required agent is unknown or disabled, and a *CycleError when the
+// constraints cannot be satisfied.
+func (s *Snapshot) Resolve(selected []*Agent) ([]*Agent, error) {
+	chain := make([]*Agent, 0, len(selected))
+	added := make(map[string]bool)
+
+	var add func(agent *Agent) error
+	add = func(agent *Agent) error {
+		if added[agent.Name] {
+			return nil
+		}
+		added[agent.Name] = true
+		for _, name := range agent.Requires {
+			required, err := s.Lookup(s.qualify(agent, name))
+			if err != nil {
+				return fmt.Errorf("agent %q requires %w", agent.Name, err)
+			}
+			if required.Disabled {
+				return fmt.Errorf("agent %q requires %q: %w", agent.Name, name, ErrAgentDisabled)
+			}
+			if err := add(required); err != nil {
+				return err
+			}
+		}
+		chain = append(chain, agent)
+		return nil
+	}
+	for _, agent := range selected {
+		if err := add(agent); err != nil {
+			return nil, err
+		}
+	}
+
+	return orderChain(chain, s.qualify)
+}
+
+// qualify returns the qualified name of the agent that from refers to by
+// name: an agent of from's namespace, or the agent Lookup finds. Names that
+// match no agent are returned unchanged.
+func (s *Snapshot) qualify(from *Agent, name string) string {
+	if from.Namespace != "" {
+		if agent := s.agents[from.Namespace+"/"+name]; agent != nil {
+			return agent.Name
+		}
+	}
+	if agent := s.Get(name); agent != nil {
+		return agent.Name
+	}
+	return name
+}
+
+// orderChain sorts chain topologically by the requires, after and before
+// constraints between its agents, resolving the names they refer to with
+// qualify. Among the agents ready to run, the one earliest in chain goes
+// first, so the sort is stable.
+func orderChain(chain []*Agent, qualify func(from *Agent, name string) string) ([]*Agent, error) {
+	index := make(map[string]int, len(chain))
+	for i, agent := range chain {
+		index[agent.Name] = i
+	}
+
+	// preds[i] holds the chain indexes of the agents that must run before chain[i].
+	preds := make([]map[int]bool, len(chain))
+	for i := range preds {
+		preds[i] = make(map[int]bool)
+	}
+	for i, agent := range chain {
+		for _, names := range [][]string{agent.Requires, agent.After} {
+			for _, name := range names {
+				if j, ok := index[qualify(agent, name)]; ok && j != i {
+					preds[i][j] = true
+				}
+			}
+		}
+		for _, name := range agent.Before {
+			if j, ok := index[qualify(agent, name)]; ok && j != i {
+				preds[j][i] = true
+			}
+		}
+	}
+
+	ordered := make([]*Agent, 0, len(chain))
+	done := make([]bool, len(chain))
+	for len(ordered) < len(chain) {
+		next := -1
+		for i := range chain {
+			if !done[i] && ready(preds[i], done) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, &CycleError{Path: findCycle(chain, preds, done)}
+		}
+		done[next] = true
+		ordered = append(ordered, chain[next])
+	}
+	return ordered, nil
+}
+
+// ready reports whether every agent in preds has been ordered.
+func ready(preds map[int]bool, done []bool) bool {
+	for j := range preds {
+		if !done[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle returns the names of a cycle among the agents not yet ordered,
+// in execution order from the agent earliest in chain. Every such agent has
+// a pending predecessor, so walking predecessors from any of them must
+// revisit an agent.
+func findCycle(chain []*Agent, preds []map[int]bool, done []bool) []string {
+	start := 0
+	for done[start] {
+		start++
+	}
+
+	visited := make(map[int]int) // chain index -> position in walk
+	var walk []int
+	for i := start; ; {
+		if pos, ok := visited[i]; ok {
+			walk = walk[pos:]
+			break
+		}
+		visited[i] = len(walk)
+		walk = append(walk, i)
+		next := -1
+		for j := range preds[i] {
+			if !done[j] && (next < 0 || j < next) {
+				next = j
+			}
+		}
+		i = next
+	}
+
+	// The walk follows predecessors; reverse it into execution order,
+	// starting from the earliest agent.
+	first := 0
+	for k, i := range walk {
+		if i < walk[first] {
+			first = k
+		}
+	}
+	path := make([]string, 0, len(walk)+1)
+	for k := 0; k <= len(walk); k++ {
+		path = append(path, chain[walk[(first-k+len(walk))%len(walk)]].Name)
+	}
+	return path
+}
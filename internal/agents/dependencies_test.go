@@ -0,0 +1,139 @@
+package agents
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry_Resolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		agents   []*Agent
+		selected []string
+		want     []string
+		wantErr  error
+		wantPath []string
+	}{
+		{
+			name: "no constraints keeps order",
+			agents: []*Agent{
+				{Name: "code-reviewer"},
+				{Name: "test-generator"},
+			},
+			selected: []string{"test-generator", "code-reviewer"},
+			want:     []string{"test-generator", "code-reviewer"},
+		},
+		{
+			name: "after reorders",
+			agents: []*Agent{
+				{Name: "coding-engineer"},
+				{Name: "test-generator", After: []string{"coding-engineer"}},
+			},
+			selected: []string{"test-generator", "coding-engineer"},
+			want:     []string{"coding-engineer", "test-generator"},
+		},
+		{
+			name: "after ignores agents outside the chain",
+			agents: []*Agent{
+				{Name: "coding-engineer"},
+				{Name: "test-generator", After: []string{"coding-engineer"}},
+			},
+			selected: []string{"test-generator"},
+			want:     []string{"test-generator"},
+		},
+		{
+			name: "before reorders",
+			agents: []*Agent{
+				{Name: "docs-writer"},
+				{Name: "linter", Before: []string{"docs-writer"}},
+			},
+			selected: []string{"docs-writer", "linter"},
+			want:     []string{"linter", "docs-writer"},
+		},
+		{
+			name: "requires pulls in agents transitively",
+			agents: []*Agent{
+				{Name: "planner"},
+				{Name: "coding-engineer", Requires: []string{"planner"}},
+				{Name: "test-generator", Requires: []string{"coding-engineer"}},
+				{Name: "code-reviewer"},
+			},
+			selected: []string{"code-reviewer", "test-generator"},
+			want:     []string{"code-reviewer", "planner", "coding-engineer", "test-generator"},
+		},
+		{
+			name: "unknown required agent",
+			agents: []*Agent{
+				{Name: "test-generator", Requires: []string{"coding-engineer"}},
+			},
+			selected: []string{"test-generator"},
+			wantErr:  ErrAgentNotFound,
+		},
+		{
+			name: "disabled required agent",
+			agents: []*Agent{
+				{Name: "coding-engineer", Disabled: true},
+				{Name: "test-generator", Requires: []string{"coding-engineer"}},
+			},
+			selected: []string{"test-generator"},
+			wantErr:  ErrAgentDisabled,
+		},
+		{
+			name: "cycle",
+			agents: []*Agent{
+				{Name: "a", Requires: []string{"b"}},
+				{Name: "b", After: []string{"c"}},
+				{Name: "c", After: []string{"a"}},
+			},
+			selected: []string{"a", "c"},
+			wantErr:  ErrDependencyCycle,
+			wantPath: []string{"b", "a", "c", "b"},
+		},
+		{
+			name: "cycle through before",
+			agents: []*Agent{
+				{Name: "a", Before: []string{"b"}},
+				{Name: "b", Before: []string{"a"}},
+			},
+			selected: []string{"a", "b"},
+			wantErr:  ErrDependencyCycle,
+			wantPath: []string{"a", "b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, agent := range tt.agents {
+				registry.Add(agent)
+			}
+			selected := make([]*Agent, len(tt.selected))
+			for i, name := range tt.selected {
+				selected[i] = registry.Get(name)
+			}
+
+			chain, err := registry.Resolve(selected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantPath != nil {
+				var cycleErr *CycleError
+				if !errors.As(err, &cycleErr) || !reflect.DeepEqual(cycleErr.Path, tt.wantPath) {
+					t.Errorf("expected cycle %v, got %v", tt.wantPath, err)
+				}
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := make([]string, len(chain))
+			for i, agent := range chain {
+				got[i] = agent.Name
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected chain %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
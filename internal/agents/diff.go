@@ -0,0 +1,36 @@
+package agents
+
+import "reflect"
+
+// Diff lists the agents that differ between two registries, by name.
+type Diff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"` // Agents whose definition changed
+}
+
+// Empty reports whether the registries hold the same agents.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffSnapshots compares the agents of old and new. Names are listed in the
+// discovery order of the snapshot they appear in.
+func DiffSnapshots(old, new *Snapshot) Diff {
+	var d Diff
+	for _, agent := range new.All() {
+		previous := old.agents[agent.Name]
+		switch {
+		case previous == nil:
+			d.Added = append(d.Added, agent.Name)
+		case !reflect.DeepEqual(previous, agent):
+			d.Changed = append(d.Changed, agent.Name)
+		}
+	}
+	for _, agent := range old.All() {
+		if new.agents[agent.Name] == nil {
+			d.Removed = append(d.Removed, agent.Name)
+		}
+	}
+	return d
+}
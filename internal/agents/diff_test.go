@@ -0,0 +1,31 @@
+package agents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffRegistries(t *testing.T) {
+	old := NewRegistry()
+	old.Add(&Agent{Name: "code-reviewer", Keywords: []string{"quality"}})
+	old.Add(&Agent{Name: "test-generator", Keywords: []string{"testing"}})
+	old.Add(&Agent{Name: "docs-writer", Keywords: []string{"docs"}})
+
+	updated := NewRegistry()
+	updated.Add(&Agent{Name: "code-reviewer", Keywords: []string{"quality"}})
+	updated.Add(&Agent{Name: "test-generator", Keywords: []string{"testing", "coverage"}})
+	updated.Add(&Agent{Name: "security/secret-scanner", Namespace: "security"})
+
+	want := Diff{
+		Added:   []string{"security/secret-scanner"},
+		Removed: []string{"docs-writer"},
+		Changed: []string{"test-generator"},
+	}
+	if got := DiffSnapshots(old.Snapshot(), updated.Snapshot()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if diff := DiffSnapshots(old.Snapshot(), old.Snapshot()); !diff.Empty() {
+		t.Errorf("expected no differences, got %+v", diff)
+	}
+}
@@ -2,20 +2,112 @@ package agents
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
 )
 
+// ParseFailure records an agent file that discovery skipped.
+type ParseFailure struct {
+	File  string `json:"file"`
+	Line  int    `json:"line,omitempty"` // Line of a frontmatter error, 0 when unknown
+	Error string `json:"error"`
+}
+
+// newParseFailure records err for file, taking the line from a
+// *FrontmatterError in err's chain.
+func newParseFailure(file string, err error) ParseFailure {
+	failure := ParseFailure{File: file, Error: err.Error()}
+	var fe *FrontmatterError
+	if errors.As(err, &fe) {
+		failure.Line = fe.Line
+	}
+	return failure
+}
+
+// Sources of agent search paths, from highest to lowest precedence.
+const (
+	SourceRepo   = "repo"   // The repository's .github/agents directory
+	SourceUser   = "user"   // The user's agents directory
+	SourceShared = "shared" // A directory listed in AGENT_PATHS
+)
+
+// Formats of agent files, recorded in Agent.Format.
+const (
+	FormatCopilotOS   = "copilot-os"   // name.md with a name and keywords in its frontmatter
+	FormatCustomAgent = "custom-agent" // A Copilot custom agent, name.agent.md
+	FormatChatMode    = "chatmode"     // A Copilot chat mode, name.chatmode.md
+	FormatPrompt      = "prompt"       // A Copilot prompt file, name.prompt.md
+)
+
+// formatSuffixes maps file name suffixes to agent file formats, longest
+// first.
+var formatSuffixes = []struct{ suffix, format string }{
+	{".agent.md", FormatCustomAgent},
+	{".chatmode.md", FormatChatMode},
+	{".prompt.md", FormatPrompt},
+	{".md", FormatCopilotOS},
+}
+
+// fileFormat returns the format of the agent file named fileName and the
+// name without its suffix. ok is false for files that are not Markdown.
+func fileFormat(fileName string) (format, stem string, ok bool) {
+	for _, s := range formatSuffixes {
+		if strings.HasSuffix(fileName, s.suffix) {
+			return s.format, strings.TrimSuffix(fileName, s.suffix), true
+		}
+	}
+	return "", "", false
+}
+
+// SearchPath is a directory agents are discovered from.
+type SearchPath struct {
+	Dir    string `json:"dir"`
+	Source string `json:"source"`           // SourceRepo, SourceUser or SourceShared
+	Format string `json:"format,omitempty"` // Only load files of this format; empty loads every format
+}
+
+// Override records an agent file that was not registered because an agent
+// of the same name was found in a search path of higher precedence.
+type Override struct {
+	Name         string `json:"name"`
+	Path         string `json:"path"`          // The overridden agent file
+	Source       string `json:"source"`        // Source of the overridden file
+	OverriddenBy string `json:"overridden_by"` // The agent file that is registered
+}
+
 // Discovery discovers and loads agents from the repository.
 type Discovery struct {
-	repoRoot string
-	logger   *zap.Logger
-	registry *Registry
+	repoRoot  string
+	logger    *zap.Logger
+	registry  *Registry
+	failures  []ParseFailure
+	overrides []Override
+	shared    []SearchPath
+}
+
+// AgentsDir returns the directory agents are discovered from in repoRoot.
+func AgentsDir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".github", "agents")
+}
+
+// RepoSearchPaths returns the directories of repoRoot agents are discovered
+// from, in order of decreasing precedence: the agents directory, then the
+// Copilot chat modes and prompt files, so the same definitions serve both
+// Copilot and copilot-os.
+func RepoSearchPaths(repoRoot string) []SearchPath {
+	return []SearchPath{
+		{Dir: AgentsDir(repoRoot), Source: SourceRepo},
+		{Dir: filepath.Join(repoRoot, ".github", "chatmodes"), Source: SourceRepo, Format: FormatChatMode},
+		{Dir: filepath.Join(repoRoot, ".github", "prompts"), Source: SourceRepo, Format: FormatPrompt},
+	}
 }
 
 // NewDiscovery creates a new agent discovery service.
@@ -27,45 +119,172 @@ func NewDiscovery(repoRoot string, logger *zap.Logger) *Discovery {
 	}
 }
 
-// Discover scans the repository for agents and populates the registry.
+// SetSearchPaths sets the directories searched after the repository's
+// agents directory, in order of decreasing precedence, such as the user's
+// agents directory and shared directories of an organization.
+func (d *Discovery) SetSearchPaths(paths []SearchPath) {
+	d.shared = paths
+}
+
+// SearchPaths returns the directories agents are discovered from, the
+// repository's directories first.
+func (d *Discovery) SearchPaths() []SearchPath {
+	return append(RepoSearchPaths(d.repoRoot), d.shared...)
+}
+
+// Discover scans the search paths for agents and populates the registry.
+//
+// Agent files in subdirectories of a search path are namespaced by their
+// directory: security/secret-scanner.md declaring name: secret-scanner
+// registers the agent security/secret-scanner. Hidden directories are
+// skipped.
+//
+// Besides copilot-os agent files, the Copilot formats are loaded: custom
+// agents (name.agent.md), chat modes (name.chatmode.md in .github/chatmodes)
+// and prompt files (name.prompt.md in .github/prompts). Their frontmatter
+// is optional and their name defaults to the file name.
+//
+// When several search paths define an agent of the same name, the one of
+// highest precedence is registered and the others are reported by
+// Overrides, so a repository can override a shared agent. Two files of the
+// same search path defining one agent are a failure.
 func (d *Discovery) Discover() error {
-	agentsDir := filepath.Join(d.repoRoot, ".github", "agents")
+	d.failures = nil
+	d.overrides = nil
+
+	discoveredCount := 0
+	for _, path := range d.SearchPaths() {
+		count, err := d.discoverPath(path)
+		if err != nil {
+			return err
+		}
+		discoveredCount += count
+	}
+	discoveredCount -= d.resolveExtends()
+
+	d.logger.Info("agent discovery complete", zap.Int("count", discoveredCount))
+	return nil
+}
+
+// resolveExtends replaces every discovered agent that extends another with
+// the merged agent. Agents whose base is missing or that extend each other
+// are removed and recorded as failures. It returns the number of agents
+// removed.
+func (d *Discovery) resolveExtends() int {
+	snapshot := d.registry.Snapshot()
+	resolver := newExtendsResolver(snapshot)
+	removed := 0
+	for _, agent := range snapshot.All() {
+		if agent.Extends == "" {
+			continue
+		}
+		merged, err := resolver.resolve(agent)
+		if err != nil {
+			d.logger.Warn("failed to resolve agent inheritance", zap.String("name", agent.Name), zap.Error(err))
+			d.failures = append(d.failures, newParseFailure(agent.Path, err))
+			d.registry.Remove(agent.Name)
+			removed++
+			continue
+		}
+		if err := d.registry.Replace(merged); err != nil {
+			d.logger.Warn("failed to replace agent", zap.String("name", agent.Name), zap.Error(err))
+		}
+	}
+	return removed
+}
+
+// discoverPath adds the agents found under path to the registry and returns
+// how many were added.
+func (d *Discovery) discoverPath(path SearchPath) (int, error) {
+	agentsDir := path.Dir
 
 	// Check if agents directory exists
 	if _, err := os.Stat(agentsDir); os.IsNotExist(err) {
-		d.logger.Warn("agents directory not found", zap.String("path", agentsDir))
-		return nil
+		if path.Source == SourceRepo && path.Format == "" {
+			d.logger.Warn("agents directory not found", zap.String("path", agentsDir))
+		} else {
+			d.logger.Debug("agents directory not found", zap.String("path", agentsDir), zap.String("source", path.Source))
+		}
+		return 0, nil
 	}
 
-	// Scan for .md files
-	entries, err := os.ReadDir(agentsDir)
-	if err != nil {
-		return fmt.Errorf("failed to read agents directory: %w", err)
+	// Agents registered from search paths of higher precedence
+	earlier := make(map[string]bool)
+	for _, agent := range d.registry.All() {
+		earlier[agent.Name] = true
 	}
 
 	discoveredCount := 0
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-			filePath := filepath.Join(agentsDir, entry.Name())
-			agent, err := d.parseAgentFile(filePath)
-			if err != nil {
-				d.logger.Warn("failed to parse agent file", zap.String("file", entry.Name()), zap.Error(err))
-				continue
-			}
+	err := walkAgentFiles(path, func(filePath, namespace string) error {
+		agent, err := d.parseAgentFile(filePath)
+		if err != nil {
+			d.logger.Warn("failed to parse agent file", zap.String("file", filePath), zap.Error(err))
+			d.failures = append(d.failures, newParseFailure(filePath, err))
+			return nil
+		}
+		agent.Source = path.Source
+		agent.setNamespace(namespace)
 
-			if agent != nil {
-				if err := d.registry.Add(agent); err != nil {
-					d.logger.Warn("failed to add agent", zap.String("name", agent.Name), zap.Error(err))
-				} else {
-					discoveredCount++
-					d.logger.Debug("discovered agent", zap.String("name", agent.Name))
-				}
-			}
+		if earlier[agent.Name] {
+			existing := d.registry.Snapshot().agents[agent.Name]
+			d.logger.Info("agent overridden",
+				zap.String("name", agent.Name),
+				zap.String("path", filePath),
+				zap.String("overridden_by", existing.Path),
+			)
+			d.overrides = append(d.overrides, Override{
+				Name:         agent.Name,
+				Path:         filePath,
+				Source:       path.Source,
+				OverriddenBy: existing.Path,
+			})
+			return nil
+		}
+
+		if err := d.registry.Add(agent); err != nil {
+			d.logger.Warn("failed to add agent", zap.String("name", agent.Name), zap.Error(err))
+			d.failures = append(d.failures, newParseFailure(filePath, err))
+		} else {
+			discoveredCount++
+			d.logger.Debug("discovered agent", zap.String("name", agent.Name), zap.String("source", path.Source))
 		}
+		return nil
+	})
+	if err != nil {
+		return discoveredCount, fmt.Errorf("failed to read agents directory %s: %w", agentsDir, err)
 	}
+	return discoveredCount, nil
+}
 
-	d.logger.Info("agent discovery complete", zap.Int("count", discoveredCount))
-	return nil
+// walkAgentFiles calls fn for each agent file of path's format under its
+// directory, with the namespace given by its subdirectory, "" at the top
+// level. Hidden directories are skipped.
+func walkAgentFiles(path SearchPath, fn func(filePath, namespace string) error) error {
+	dir := path.Dir
+	return filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if format, _, ok := fileFormat(entry.Name()); !ok || (path.Format != "" && format != path.Format) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+		namespace := ""
+		if rel != "." {
+			namespace = filepath.ToSlash(rel)
+		}
+		return fn(filePath, namespace)
+	})
 }
 
 // Registry returns the populated registry.
@@ -73,60 +292,138 @@ func (d *Discovery) Registry() *Registry {
 	return d.registry
 }
 
+// Failures returns the agent files the last Discover skipped because they
+// could not be parsed or added to the registry.
+func (d *Discovery) Failures() []ParseFailure {
+	return d.failures
+}
+
+// Overrides returns the agent files the last Discover skipped because a
+// search path of higher precedence defines the same agent.
+func (d *Discovery) Overrides() []Override {
+	return d.overrides
+}
+
+// errNameMissing reports an agent file whose frontmatter has no name.
+var errNameMissing = errors.New("agent name not found in frontmatter")
+
 // parseAgentFile parses a Markdown agent file with YAML frontmatter.
+// Invalid frontmatter fails with a *FrontmatterError carrying the line of
+// the file; unknown keys and unquoted values are logged as warnings.
 func (d *Discovery) parseAgentFile(filePath string) (*Agent, error) {
+	file, err := readAgentFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range slices.Concat(file.unquoted, file.warnings) {
+		d.logger.Warn("agent frontmatter warning",
+			zap.String("file", filePath),
+			zap.Int("line", warning.Line),
+			zap.String("warning", warning.Msg),
+		)
+	}
+	return file.agent, nil
+}
+
+// agentFile is a parsed agent file.
+type agentFile struct {
+	agent    *Agent
+	lines    map[string]int      // Line of each frontmatter key in the file
+	warnings []*FrontmatterError // Unknown frontmatter keys
+	unquoted []*FrontmatterError // Values that should be quoted
+}
+
+// readAgentFile reads and parses the agent file at filePath. The agent has
+// the name declared in its frontmatter, without namespace. Files of the
+// Copilot formats need no frontmatter and default to the file's name.
+func readAgentFile(filePath string) (*agentFile, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	format, stem, _ := fileFormat(filepath.Base(filePath))
 
 	// Extract YAML frontmatter (between --- delimiters)
-	frontmatter, err := extractFrontmatter(string(content))
-	if err != nil {
+	text, err := extractFrontmatter(string(content))
+	switch {
+	case format != FormatCopilotOS && (err != nil || text == ""):
+		text = ""
+	case err != nil:
 		return nil, fmt.Errorf("failed to extract frontmatter: %w", err)
+	case text == "":
+		return nil, fmt.Errorf("no frontmatter found")
 	}
 
-	if frontmatter == "" {
-		return nil, fmt.Errorf("no frontmatter found")
+	// Lines before the frontmatter text: the opening delimiter and any
+	// blank lines following it.
+	offset := 0
+	if text != "" {
+		offset = strings.Count(string(content)[:strings.Index(string(content), text)], "\n")
+	}
+
+	fm, warnings, err := parseFrontmatter(text, offset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	// Validate required fields
+	if fm.Name == "" {
+		if format == FormatCopilotOS {
+			return nil, errNameMissing
+		}
+		fm.Name = stem
+	}
+	if strings.Contains(fm.Name, "/") {
+		return nil, fmt.Errorf("agent name %q must not contain /: namespaces come from subdirectories", fm.Name)
 	}
 
-	// Parse YAML frontmatter
 	agent := &Agent{
-		Keywords: []string{},
-	}
-
-	// Simple YAML parsing (handles our use case)
-	lines := strings.Split(frontmatter, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "name:") {
-			agent.Name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
-		} else if strings.HasPrefix(line, "description:") {
-			agent.Description = strings.TrimSpace(strings.TrimPrefix(line, "description:"))
-		} else if strings.HasPrefix(line, "keywords:") {
-			// Parse keywords array [key1, key2, key3]
-			keywordsStr := strings.TrimSpace(strings.TrimPrefix(line, "keywords:"))
-			if strings.HasPrefix(keywordsStr, "[") && strings.HasSuffix(keywordsStr, "]") {
-				keywordsStr = strings.TrimPrefix(keywordsStr, "[")
-				keywordsStr = strings.TrimSuffix(keywordsStr, "]")
-				parts := strings.Split(keywordsStr, ",")
-				for _, part := range parts {
-					kw := strings.TrimSpace(part)
-					kw = strings.Trim(kw, "\"'")
-					if kw != "" {
-						agent.Keywords = append(agent.Keywords, kw)
-					}
-				}
-			}
+		Name:         fm.Name,
+		Description:  fm.Description,
+		Keywords:     []string(fm.Keywords),
+		Arguments:    []string(fm.Arguments),
+		Requires:     []string(fm.Requires),
+		After:        []string(fm.After),
+		Before:       []string(fm.Before),
+		Path:         filePath,
+		Format:       format,
+		Tools:        []string(fm.Tools),
+		Instructions: extractBody(string(content)),
+	}
+	if agent.Keywords == nil {
+		agent.Keywords = []string{}
+	}
+	fm.apply(agent)
+	if fm.Extends != "" {
+		agent.Extends = fm.Extends
+		agent.inheritance = &inheritance{
+			declared:       make(map[string]bool, len(fm.lines)),
+			appended:       fm.appended,
+			appendSections: []string(fm.AppendSections),
+		}
+		for key := range fm.lines {
+			agent.inheritance.declared[key] = true
 		}
 	}
 
-	// Validate required fields
-	if agent.Name == "" {
-		return nil, fmt.Errorf("agent name not found in frontmatter")
+	return &agentFile{agent: agent, lines: fm.lines, warnings: warnings, unquoted: fm.unquoted}, nil
+}
+
+// LoadInstructions reads an agent file and returns the Markdown instructions
+// that follow its frontmatter.
+func LoadInstructions(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
+	return extractBody(string(content)), nil
+}
 
-	return agent, nil
+// extractBody returns the content following the frontmatter, with
+// surrounding whitespace trimmed. Content without frontmatter is returned as-is.
+func extractBody(content string) string {
+	re := regexp.MustCompile(`^---\s*\n[\s\S]*?\n---[^\n]*\n?`)
+	return strings.TrimSpace(re.ReplaceAllString(content, ""))
 }
 
 // extractFrontmatter extracts YAML frontmatter from content.
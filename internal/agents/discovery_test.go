@@ -1,8 +1,11 @@
 package agents
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -175,6 +178,219 @@ keywords: [two, second]
 	if agent2 == nil {
 		t.Error("expected to find agent2")
 	}
+
+	// invalid.md should be reported as a parse failure
+	failures := discovery.Failures()
+	if len(failures) != 1 || failures[0].File != filepath.Join(agentsDir, "invalid.md") || failures[0].Error == "" {
+		t.Errorf("expected a parse failure for invalid.md, got %+v", failures)
+	}
+}
+
+func TestDiscovery_Discover_Namespaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	agentsDir := filepath.Join(tmpDir, ".github", "agents")
+
+	files := map[string]string{
+		"code-reviewer.md":               "---\nname: code-reviewer\n---\n",
+		"security/secret-scanner.md":     "---\nname: secret-scanner\nrequires: [dependency-auditor]\n---\n",
+		"security/dependency-auditor.md": "---\nname: dependency-auditor\n---\n",
+		"security/web/xss-checker.md":    "---\nname: xss-checker\n---\n",
+		".drafts/unfinished.md":          "---\nname: unfinished\n---\n",
+		"frontend/invalid.md":            "---\nname: frontend/invalid\n---\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(agentsDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	discovery := NewDiscovery(tmpDir, zap.NewNop())
+	if err := discovery.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry := discovery.Registry()
+
+	names := make(map[string]string)
+	for _, agent := range registry.All() {
+		names[agent.Name] = agent.Namespace
+	}
+	want := map[string]string{
+		"code-reviewer":               "",
+		"security/secret-scanner":     "security",
+		"security/dependency-auditor": "security",
+		"security/web/xss-checker":    "security/web",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected agents %v, got %v", want, names)
+	}
+
+	// Requirements are resolved within the declaring agent's namespace.
+	chain, err := registry.Resolve([]*Agent{registry.Get("secret-scanner")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chain) != 2 || chain[0].Name != "security/dependency-auditor" {
+		t.Errorf("expected security/dependency-auditor to be required, got %v", chain)
+	}
+
+	// Names must not carry their own namespace.
+	failures := discovery.Failures()
+	if len(failures) != 1 || !strings.Contains(failures[0].Error, "must not contain /") {
+		t.Errorf("expected a failure for frontend/invalid.md, got %+v", failures)
+	}
+}
+
+func TestDiscovery_Discover_SearchPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := AgentsDir(filepath.Join(tmpDir, "repo"))
+	userDir := filepath.Join(tmpDir, "user")
+	orgDir := filepath.Join(tmpDir, "org")
+
+	files := map[string]string{
+		filepath.Join(repoDir, "code-reviewer.md"):             "---\nname: code-reviewer\ndescription: repo\n---\n",
+		filepath.Join(userDir, "code-reviewer.md"):             "---\nname: code-reviewer\ndescription: user\n---\n",
+		filepath.Join(userDir, "test-generator.md"):            "---\nname: test-generator\ndescription: user\n---\n",
+		filepath.Join(orgDir, "test-generator.md"):             "---\nname: test-generator\ndescription: org\n---\n",
+		filepath.Join(orgDir, "security", "secret-scanner.md"): "---\nname: secret-scanner\n---\n",
+		filepath.Join(orgDir, "security-copy.md"):              "---\nname: linter\n---\n",
+		filepath.Join(orgDir, "linter.md"):                     "---\nname: linter\n---\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	discovery := NewDiscovery(filepath.Join(tmpDir, "repo"), zap.NewNop())
+	discovery.SetSearchPaths([]SearchPath{
+		{Dir: userDir, Source: SourceUser},
+		{Dir: orgDir, Source: SourceShared},
+		{Dir: filepath.Join(tmpDir, "missing"), Source: SourceShared},
+	})
+	if err := discovery.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry := discovery.Registry()
+
+	tests := []struct {
+		name        string
+		description string
+		source      string
+	}{
+		{"code-reviewer", "repo", SourceRepo},
+		{"test-generator", "user", SourceUser},
+		{"security/secret-scanner", "", SourceShared},
+		{"linter", "", SourceShared},
+	}
+	for _, tt := range tests {
+		agent := registry.Get(tt.name)
+		if agent == nil {
+			t.Errorf("expected agent %s", tt.name)
+			continue
+		}
+		if agent.Description != tt.description || agent.Source != tt.source {
+			t.Errorf("%s: expected %s agent from %s, got %q from %s", tt.name, tt.description, tt.source, agent.Description, agent.Source)
+		}
+	}
+
+	overrides := discovery.Overrides()
+	if len(overrides) != 2 {
+		t.Fatalf("expected 2 overrides, got %+v", overrides)
+	}
+	want := Override{
+		Name:         "code-reviewer",
+		Path:         filepath.Join(userDir, "code-reviewer.md"),
+		Source:       SourceUser,
+		OverriddenBy: filepath.Join(repoDir, "code-reviewer.md"),
+	}
+	if overrides[0] != want {
+		t.Errorf("expected override %+v, got %+v", want, overrides[0])
+	}
+
+	// Two files of one search path defining the same agent still fail.
+	failures := discovery.Failures()
+	var duplicate *DuplicateError
+	if len(failures) != 1 || !strings.Contains(failures[0].Error, "already defined in") {
+		t.Errorf("expected a duplicate failure for linter, got %+v", failures)
+	}
+	if err := registry.Add(&Agent{Name: "linter", Path: "other.md"}); !errors.As(err, &duplicate) || duplicate.ExistingPath == "" {
+		t.Errorf("expected *DuplicateError with the existing path, got %v", err)
+	}
+}
+
+func TestDiscovery_Discover_CopilotFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	github := filepath.Join(tmpDir, ".github")
+
+	files := map[string]string{
+		"agents/code-reviewer.md":          "---\nname: code-reviewer\nkeywords: [code-review]\n---\nReview code.",
+		"agents/planner.agent.md":          "---\ndescription: Plans work\ntools: ['codebase', 'search']\nmodel: Claude Sonnet 4\n---\nPlan first.",
+		"chatmodes/review.chatmode.md":     "---\ndescription: Review mode\ntools: [codebase]\n---\nReview carefully.",
+		"chatmodes/README.md":              "# Chat modes",
+		"prompts/release-notes.prompt.md":  "Write release notes.",
+		"prompts/review.prompt.md":         "---\nmode: agent\n---\nReview quickly.",
+		"prompts/security/audit.prompt.md": "---\ndescription: Audit\n---\nAudit.",
+	}
+	for name, content := range files {
+		path := filepath.Join(github, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	discovery := NewDiscovery(tmpDir, zap.NewNop())
+	if err := discovery.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry := discovery.Registry()
+
+	tests := []struct {
+		name         string
+		format       string
+		tools        []string
+		model        string
+		instructions string
+		copilot      bool
+	}{
+		{"code-reviewer", FormatCopilotOS, nil, "", "Review code.", true},
+		{"planner", FormatCustomAgent, []string{"codebase", "search"}, "Claude Sonnet 4", "Plan first.", true},
+		{"review", FormatChatMode, []string{"codebase"}, "", "Review carefully.", false},
+		{"release-notes", FormatPrompt, nil, "", "Write release notes.", false},
+		{"security/audit", FormatPrompt, nil, "", "Audit.", false},
+	}
+	for _, tt := range tests {
+		agent := registry.Get(tt.name)
+		if agent == nil {
+			t.Errorf("expected agent %s", tt.name)
+			continue
+		}
+		if agent.Format != tt.format || agent.Source != SourceRepo || !reflect.DeepEqual(agent.Tools, tt.tools) ||
+			agent.Model != tt.model || agent.Instructions != tt.instructions || agent.CopilotAgent() != tt.copilot {
+			t.Errorf("%s: unexpected agent %+v", tt.name, agent)
+		}
+	}
+	if got := len(registry.All()); got != len(tests) {
+		t.Errorf("expected %d agents, got %d", len(tests), got)
+	}
+
+	// Chat modes take precedence over prompt files of the same name.
+	overrides := discovery.Overrides()
+	if len(overrides) != 1 || filepath.Base(overrides[0].Path) != "review.prompt.md" {
+		t.Errorf("expected review.prompt.md to be overridden, got %+v", overrides)
+	}
+	if failures := discovery.Failures(); len(failures) != 0 {
+		t.Errorf("expected no failures, got %+v", failures)
+	}
 }
 
 func TestDiscovery_Discover_NoAgentsDir(t *testing.T) {
@@ -288,3 +504,72 @@ description: multi
 		})
 	}
 }
+
+func TestDiscovery_ParseAgentFile_Arguments(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "reviewer.md")
+	content := `---
+name: reviewer
+description: Reviews code
+arguments: [file, "focus"]
+---
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(agent.Arguments) != 2 || agent.Arguments[0] != "file" || agent.Arguments[1] != "focus" {
+		t.Errorf("expected arguments [file focus], got %v", agent.Arguments)
+	}
+
+	if agent.Path != tmpFile {
+		t.Errorf("expected path %q, got %q", tmpFile, agent.Path)
+	}
+}
+
+func TestDiscovery_ParseAgentFile_Instructions(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "reviewer.md")
+	content := "---\nname: reviewer\n---\n\n# Reviewer\n\nCheck everything.\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Reviewer\n\nCheck everything."
+	if agent.Instructions != expected {
+		t.Errorf("expected instructions %q, got %q", expected, agent.Instructions)
+	}
+}
+
+func TestLoadInstructions(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "reviewer.md")
+	content := `---
+name: reviewer
+---
+
+# Reviewer
+
+Check everything.
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	instructions, err := LoadInstructions(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Reviewer\n\nCheck everything."
+	if instructions != expected {
+		t.Errorf("expected instructions %q, got %q", expected, instructions)
+	}
+}
@@ -14,6 +14,49 @@
 //   - name: Agent identifier
 //   - description: What the agent does
 //   - keywords: Capabilities and domains (used for matching)
+//   - arguments: Optional named inputs, exposed as MCP prompt arguments
+//
+// Optional execution settings override the server's defaults for the agent:
+//   - timeout: Per-invocation timeout, e.g. 90s or 5m
+//   - retries: Retries after a failed run
+//   - model: Model requested from the backend
+//   - backend: cli, sampling or auto
+//   - max_output_bytes: Output kept per invocation
+//   - enabled: false keeps the agent registered but never selected or run;
+//     running it explicitly fails with ErrAgentDisabled
+//
+// Agents may declare how they are ordered in a chain:
+//   - requires: Agents added to the chain and run before this one
+//   - after: Agents that run before this one when in the same chain
+//   - before: Agents that run after this one when in the same chain
+//
+// Registry.Resolve applies these to a selection, returning a *CycleError when
+// they cannot be satisfied.
+//
+// Besides the repository's .github/agents/, Discovery searches the
+// directories given to SetSearchPaths, such as the user's agents directory
+// and shared directories of an organization. Search paths are ordered by
+// precedence, the repository first: an agent found in several of them is
+// registered from the first and the others are reported by Overrides. Each
+// agent records its Path and the Source of its search path.
+//
+// The agent files of GitHub Copilot are loaded too, so one repository's
+// definitions work in both Copilot and copilot-os: custom agents
+// (name.agent.md in an agents directory), chat modes (name.chatmode.md in
+// .github/chatmodes/) and prompt files (name.prompt.md in .github/prompts/).
+// Their frontmatter is optional, the name defaults to the file name, and
+// their tools are kept in Agent.Tools. Each agent records its Format.
+// Agent.CopilotAgent reports whether the Copilot CLI can load an agent by
+// name; chat modes, prompt files, namespaced agents and agents from the user
+// and shared search paths, which the CLI does not resolve, are run with their
+// instructions instead. In a repository, .github/agents/ takes precedence over chat
+// modes, and chat modes over prompt files.
+//
+// Subdirectories of .github/agents/ are namespaces: security/secret-scanner.md
+// declaring name: secret-scanner registers the agent security/secret-scanner.
+// Registry.Get and Registry.Lookup also find a namespaced agent by its short
+// name when it is unique, and Registry.InNamespace lists the agents of a
+// namespace.
 //
 // Example agent file:
 //
@@ -29,6 +72,22 @@
 //	# Code Reviewer Agent Instructions
 //	...
 //
+// The frontmatter is parsed as YAML into a typed structure, so block lists,
+// block scalars and quoted values work as in any YAML document. Invalid
+// frontmatter fails with a *FrontmatterError carrying the line in the agent
+// file; unknown keys are logged as warnings and ignored. For compatibility
+// with the former line-based parser, a name or description that is not
+// valid YAML unquoted, such as one containing ": " or starting with "@", is
+// read to the end of its line with a warning, and
+// keywords and arguments may also be given as a comma-separated string.
+//
+// The Markdown body following the frontmatter is kept as the agent's
+// Instructions, so backends that do not read agent files can be given them.
+//
+// Files that cannot be parsed are skipped with a warning and reported by
+// Discovery.Failures, with the line of frontmatter errors, so tools such as
+// copilot-os doctor can show them.
+//
 // # Agent Registry
 //
 // The Registry maintains discovered agents and provides methods for:
@@ -68,8 +127,77 @@
 //	keywords := []string{"code", "review", "quality"}
 //	matchedAgents := registry.MatchKeywords(keywords)
 //
+// # Inheritance
+//
+// An agent file may extend another agent with extends:, naming it like a
+// requires entry. After walking the search paths, Discover merges each such
+// agent with its base, recursively, and registers the merged agent:
+//
+//   - description, keywords, arguments, tools, requires, after, before and the
+//     execution settings the file does not declare are inherited; declared
+//     ones override the base
+//   - a list given as {append: [...]} adds its items to the base's list
+//   - enabled is not inherited, so a disabled base can serve as a template
+//   - instructions are merged by Markdown section: a section replaces the
+//     base's section of the same heading, or is appended to it when the
+//     heading is listed in append_sections, and new sections follow the
+//     base's; the text before the first heading is a section too
+//
+// For example, a reviewer adding Go checks to code-reviewer:
+//
+//	---
+//	name: go-reviewer
+//	extends: code-reviewer
+//	keywords:
+//	  append: [golang]
+//	append_sections: [Checklist]
+//	---
+//	## Checklist
+//	- Errors are wrapped with %w
+//
+// An agent whose base is not registered, or that is part of an inheritance
+// cycle, is skipped and reported in Failures with an error wrapping a
+// *NotFoundError or an *InheritanceCycleError; agents extending it are
+// skipped too. Merged agents keep Extends, and the Copilot CLI receives
+// their instructions with the prompt, as their file holds only part of them.
+//
+// # Linting
+//
+// Discover logs and skips agent files it cannot load. Discovery.Lint checks
+// the same files without registering them and reports each problem as a
+// LintIssue with its file, line, rule and severity. Errors are the files
+// Discover would skip: invalid frontmatter, a missing name, a name defined
+// twice in one search path, or an extends naming a missing agent or forming
+// a cycle. Warnings are files that load but are
+// likely mistakes: a name that differs from the file name, an empty
+// description, unknown frontmatter keys, keywords that are not in the
+// vocabulary of keywords prompts produce, and keywords that are all keywords
+// of another agent, which then scores at least as high on every prompt.
+//
+//	report, err := discovery.Lint(prompt.Keywords())
+//	if err != nil {
+//	    return err
+//	}
+//	for _, issue := range report.Issues {
+//	    fmt.Printf("%s:%d: %s: %s\n", issue.File, issue.Line, issue.Severity, issue.Message)
+//	}
+//
 // # Thread Safety
 //
-// The Registry is not thread-safe. If concurrent access is needed, external
-// synchronization must be used (e.g., sync.RWMutex).
+// The Registry is safe for concurrent use. Add, Replace, Remove and
+// ReplaceAll apply each change to a copy of the agent set and publish it
+// atomically, so readers never block and never see a partial change.
+//
+// Snapshot returns the current agent set as an immutable view. Every change
+// increments the registry version, and a snapshot keeps its version and
+// agents however the registry changes afterwards. Callers that make several
+// lookups, such as the orchestrator planning a chain, should take one
+// snapshot and use it throughout:
+//
+//	snapshot := registry.Snapshot()
+//	matched := snapshot.MatchKeywords(keywords)
+//	chain, err := snapshot.Resolve(matched)
+//	log.Printf("planned with registry version %d", snapshot.Version())
+//
+// Agents returned by the registry are shared and must not be modified.
 package agents
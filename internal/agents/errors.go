@@ -0,0 +1,103 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAgentNotFound is matched by errors.Is for every lookup of an agent that
+// is not in the registry.
+var ErrAgentNotFound = errors.New("agent not found")
+
+// ErrAgentDisabled is returned when running an agent whose frontmatter sets
+// enabled: false.
+var ErrAgentDisabled = errors.New("agent is disabled")
+
+// ErrDependencyCycle is matched by errors.Is for every *CycleError.
+var ErrDependencyCycle = errors.New("agent dependency cycle")
+
+// ErrInheritanceCycle is matched by errors.Is for every
+// *InheritanceCycleError.
+var ErrInheritanceCycle = errors.New("agent inheritance cycle")
+
+// CycleError reports agents whose requires, after and before declarations
+// form a cycle. Path lists the agents in execution order, starting and
+// ending with the same agent.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("agent dependency cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// Is reports whether target is ErrDependencyCycle.
+func (e *CycleError) Is(target error) bool {
+	return target == ErrDependencyCycle
+}
+
+// InheritanceCycleError reports agents that extend each other. Path lists
+// the agents from one of them to the agent it extends, and so on, ending
+// with the first agent again.
+type InheritanceCycleError struct {
+	Path []string
+}
+
+func (e *InheritanceCycleError) Error() string {
+	return fmt.Sprintf("agent inheritance cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// Is reports whether target is ErrInheritanceCycle.
+func (e *InheritanceCycleError) Is(target error) bool {
+	return target == ErrInheritanceCycle
+}
+
+// NotFoundError reports a lookup of an unknown agent. It matches
+// ErrAgentNotFound with errors.Is.
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("agent %q not found", e.Name)
+}
+
+// Is reports whether target is ErrAgentNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrAgentNotFound
+}
+
+// DuplicateError reports an agent added to a registry that already holds an
+// agent of the same name. Discovery only reports it for two files of one
+// search path; across search paths the file of higher precedence overrides
+// the other.
+type DuplicateError struct {
+	Name         string
+	Path         string // File of the rejected agent, empty if not loaded from disk
+	ExistingPath string // File of the registered agent, empty if not loaded from disk
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Path == "" || e.ExistingPath == "" {
+		return fmt.Sprintf("agent %q already registered", e.Name)
+	}
+	return fmt.Sprintf("agent %q in %s already defined in %s", e.Name, e.Path, e.ExistingPath)
+}
+
+// AmbiguousError reports a lookup of a name without a namespace that matches
+// agents in several namespaces. It matches ErrAgentNotFound with errors.Is,
+// as no single agent was found.
+type AmbiguousError struct {
+	Name    string
+	Matches []string // Qualified names of the matching agents
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("agent %q is ambiguous: use one of %s", e.Name, strings.Join(e.Matches, ", "))
+}
+
+// Is reports whether target is ErrAgentNotFound.
+func (e *AmbiguousError) Is(target error) bool {
+	return target == ErrAgentNotFound
+}
@@ -0,0 +1,312 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterError reports an invalid agent frontmatter at a line of the
+// agent file.
+type FrontmatterError struct {
+	Line int // 1-based line in the agent file, 0 when unknown
+	Msg  string
+}
+
+func (e *FrontmatterError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// frontmatter is the typed YAML frontmatter of an agent file.
+type frontmatter struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Keywords    stringList `yaml:"keywords"`
+	Arguments   stringList `yaml:"arguments"`
+
+	Requires stringList `yaml:"requires"`
+	After    stringList `yaml:"after"`
+	Before   stringList `yaml:"before"`
+
+	Extends        string     `yaml:"extends"`
+	AppendSections stringList `yaml:"append_sections"`
+
+	Timeout        duration `yaml:"timeout"`
+	Retries        *count   `yaml:"retries"`
+	Model          string   `yaml:"model"`
+	Backend        backend  `yaml:"backend"`
+	MaxOutputBytes count    `yaml:"max_output_bytes"`
+	Enabled        *bool    `yaml:"enabled"`
+
+	// Keys of the Copilot formats. mode and argument-hint are accepted so
+	// prompt files load without warnings, but are not used.
+	Tools        stringList `yaml:"tools"`
+	Mode         string     `yaml:"mode"`
+	ArgumentHint string     `yaml:"argument-hint"`
+
+	lines    map[string]int      // Line of each key in the agent file
+	appended map[string]bool     // List keys given as {append: [...]}
+	unquoted []*FrontmatterError // Values accepted as the former parser did; see quoteLegacyValues
+}
+
+// fields maps each known frontmatter key to the field it is decoded into.
+func (fm *frontmatter) fields() map[string]any {
+	return map[string]any{
+		"name":        &fm.Name,
+		"description": &fm.Description,
+		"keywords":    &fm.Keywords,
+		"arguments":   &fm.Arguments,
+
+		"requires": &fm.Requires,
+		"after":    &fm.After,
+		"before":   &fm.Before,
+
+		"extends":         &fm.Extends,
+		"append_sections": &fm.AppendSections,
+
+		"timeout":          &fm.Timeout,
+		"retries":          &fm.Retries,
+		"model":            &fm.Model,
+		"backend":          &fm.Backend,
+		"max_output_bytes": &fm.MaxOutputBytes,
+		"enabled":          &fm.Enabled,
+
+		"tools":         &fm.Tools,
+		"mode":          &fm.Mode,
+		"argument-hint": &fm.ArgumentHint,
+	}
+}
+
+// apply copies the execution settings to agent.
+func (fm *frontmatter) apply(agent *Agent) {
+	agent.Timeout = time.Duration(fm.Timeout)
+	if fm.Retries != nil {
+		retries := int(*fm.Retries)
+		agent.Retries = &retries
+	}
+	agent.Model = strings.TrimSpace(fm.Model)
+	agent.Backend = string(fm.Backend)
+	agent.MaxOutputBytes = int(fm.MaxOutputBytes)
+	agent.Disabled = fm.Enabled != nil && !*fm.Enabled
+}
+
+// duration is a positive Go duration string such as 90s or 5m.
+type duration time.Duration
+
+// UnmarshalYAML parses a duration string.
+func (d *duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if node.Kind != yaml.ScalarNode || err != nil || parsed <= 0 {
+		return fmt.Errorf("expected a positive duration such as 90s or 5m")
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// count is a non-negative integer.
+type count int
+
+// UnmarshalYAML decodes a non-negative integer.
+func (c *count) UnmarshalYAML(node *yaml.Node) error {
+	var n int
+	if err := node.Decode(&n); err != nil || n < 0 {
+		return fmt.Errorf("expected a non-negative integer")
+	}
+	*c = count(n)
+	return nil
+}
+
+// backend is the name of an agent invocation backend.
+type backend string
+
+// UnmarshalYAML accepts the backends the server supports.
+func (b *backend) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Value {
+	case "cli", "sampling", "auto":
+		*b = backend(node.Value)
+		return nil
+	}
+	return fmt.Errorf("expected cli, sampling or auto")
+}
+
+// stringList is a YAML list of strings. For compatibility with agent files
+// written for the former line-based parser, a plain scalar is accepted as a
+// comma-separated list.
+type stringList []string
+
+// UnmarshalYAML decodes a sequence of strings or a comma-separated scalar.
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*l = nil
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*l = append(*l, item)
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	default:
+		return fmt.Errorf("expected a list of strings")
+	}
+}
+
+// yamlLinePattern matches the line prefix of yaml.v3 error messages.
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// parseFrontmatter decodes the YAML frontmatter text of an agent file.
+// offset is the number of file lines before the frontmatter, so errors carry
+// lines of the file rather than of the frontmatter.
+//
+// Syntax errors, values of the wrong type and duplicate keys are returned as
+// a *FrontmatterError. Unknown keys do not fail the file, so agent files can
+// carry metadata for other tools; they are returned as warnings. When the
+// text is not valid YAML, a name or description that is not a valid YAML
+// value unquoted is read as the former line-based parser did and recorded in
+// the frontmatter's unquoted warnings.
+func parseFrontmatter(text string, offset int) (*frontmatter, []*FrontmatterError, error) {
+	fm := &frontmatter{lines: make(map[string]int), appended: make(map[string]bool)}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		quoted, lines := quoteLegacyValues(text)
+		if len(lines) == 0 || yaml.Unmarshal([]byte(quoted), &doc) != nil {
+			return nil, nil, yamlError(err, offset)
+		}
+		for key, line := range lines {
+			fm.unquoted = append(fm.unquoted, &FrontmatterError{
+				Line: line + offset,
+				Msg:  fmt.Sprintf("%s: quote this value; unquoted, it is not valid YAML", key),
+			})
+		}
+		slices.SortFunc(fm.unquoted, func(a, b *FrontmatterError) int { return a.Line - b.Line })
+	}
+	if len(doc.Content) == 0 {
+		return fm, nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, &FrontmatterError{Line: root.Line + offset, Msg: "frontmatter must be a mapping of keys to values"}
+	}
+
+	fields := fm.fields()
+	seen := make(map[string]bool)
+	var warnings []*FrontmatterError
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		field, ok := fields[key.Value]
+		if !ok {
+			warnings = append(warnings, &FrontmatterError{Line: key.Line + offset, Msg: fmt.Sprintf("unknown key %q", key.Value)})
+			continue
+		}
+		if seen[key.Value] {
+			return nil, nil, &FrontmatterError{Line: key.Line + offset, Msg: fmt.Sprintf("duplicate key %q", key.Value)}
+		}
+		seen[key.Value] = true
+		fm.lines[key.Value] = key.Line + offset
+
+		// An extending agent appends to its base's list with {append: [...]}.
+		if value.Kind == yaml.MappingNode && inheritedLists[key.Value] != nil {
+			if len(value.Content) != 2 || value.Content[0].Value != "append" {
+				return nil, nil, &FrontmatterError{Line: value.Line + offset, Msg: fmt.Sprintf("%s: expected a list of strings or {append: [...]}", key.Value)}
+			}
+			fm.appended[key.Value] = true
+			value = value.Content[1]
+		}
+
+		if err := value.Decode(field); err != nil {
+			return nil, nil, &FrontmatterError{Line: value.Line + offset, Msg: fmt.Sprintf("%s: %s", key.Value, decodeMessage(err))}
+		}
+	}
+
+	fm.Name = strings.TrimSpace(fm.Name)
+	fm.Description = strings.TrimSpace(fm.Description)
+	fm.Extends = strings.TrimSpace(fm.Extends)
+	if fm.Extends == "" {
+		for key := range fm.appended {
+			return nil, nil, &FrontmatterError{Line: fm.lines[key], Msg: fmt.Sprintf("%s: append requires extends", key)}
+		}
+		if len(fm.AppendSections) > 0 {
+			return nil, nil, &FrontmatterError{Line: fm.lines["append_sections"], Msg: "append_sections requires extends"}
+		}
+	}
+	return fm, warnings, nil
+}
+
+// quoteLegacyValues quotes the top-level name and description values of text
+// that are not valid YAML values on their own line, such as values that
+// contain ": " or start with "@", "`" or "- ". The former line-based parser
+// read each value to the end of its line, so such values were valid; YAML
+// rejects them. Values that start with a quote or a block scalar indicator
+// are left to YAML. It returns the quoted text and the 1-based line of each
+// quoted key.
+func quoteLegacyValues(text string) (string, map[string]int) {
+	lines := strings.Split(text, "\n")
+	quoted := make(map[string]int)
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || (key != "name" && key != "description") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || strings.ContainsAny(value[:1], `"'|>`) || scalarValue(value) {
+			continue
+		}
+		lines[i] = key + ": " + strconv.Quote(value)
+		quoted[key] = i + 1
+	}
+	return strings.Join(lines, "\n"), quoted
+}
+
+// scalarValue reports whether value parses as a single YAML scalar when
+// written after a key.
+func scalarValue(value string) bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("value: "+value), &doc); err != nil {
+		return false
+	}
+	return len(doc.Content) == 1 && len(doc.Content[0].Content) == 2 && doc.Content[0].Content[1].Kind == yaml.ScalarNode
+}
+
+// yamlError converts a yaml.v3 syntax error to a *FrontmatterError with the
+// line shifted by offset.
+func yamlError(err error, offset int) error {
+	msg := err.Error()
+	fe := &FrontmatterError{Msg: strings.TrimPrefix(msg, "yaml: ")}
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		fe.Line = line + offset
+		fe.Msg = msg[len(m[0]):]
+	}
+	if strings.Contains(fe.Msg, "mapping values are not allowed") {
+		fe.Msg += ` (quote values that contain ": ")`
+	}
+	return fe
+}
+
+// decodeMessage returns the message of a decoding error without the line
+// prefix yaml.v3 adds to type errors.
+func decodeMessage(err error) string {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		return yamlLinePattern.ReplaceAllString(typeErr.Errors[0], "")
+	}
+	return err.Error()
+}
@@ -0,0 +1,345 @@
+package agents
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestDiscovery_ParseAgentFile_YAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantName string
+		wantDesc string
+		wantKW   []string
+		wantArgs []string
+	}{
+		{
+			name: "block lists",
+			content: `---
+name: reviewer
+keywords:
+  - review
+  - "code quality"
+arguments:
+  - file
+---
+`,
+			wantName: "reviewer",
+			wantKW:   []string{"review", "code quality"},
+			wantArgs: []string{"file"},
+		},
+		{
+			name: "folded description",
+			content: `---
+name: reviewer
+description: >
+  Reviews code for
+  correctness.
+---
+`,
+			wantName: "reviewer",
+			wantDesc: "Reviews code for correctness.",
+			wantKW:   []string{},
+		},
+		{
+			name: "quoted value with colon",
+			content: `---
+name: "reviewer"
+description: "Reviews code: Go and Rust"
+---
+`,
+			wantName: "reviewer",
+			wantDesc: "Reviews code: Go and Rust",
+			wantKW:   []string{},
+		},
+		{
+			name: "comma-separated keywords",
+			content: `---
+name: reviewer
+keywords: review, testing
+---
+`,
+			wantName: "reviewer",
+			wantKW:   []string{"review", "testing"},
+		},
+		{
+			name: "unknown and nested keys are ignored",
+			content: `---
+name: reviewer
+tools: [read, search]
+metadata:
+  owner: platform
+---
+`,
+			wantName: "reviewer",
+			wantKW:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "agent.md")
+			if err := os.WriteFile(tmpFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			agent, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if agent.Name != tt.wantName {
+				t.Errorf("expected name %q, got %q", tt.wantName, agent.Name)
+			}
+			if agent.Description != tt.wantDesc {
+				t.Errorf("expected description %q, got %q", tt.wantDesc, agent.Description)
+			}
+			if !reflect.DeepEqual(agent.Keywords, tt.wantKW) {
+				t.Errorf("expected keywords %v, got %v", tt.wantKW, agent.Keywords)
+			}
+			if !reflect.DeepEqual(agent.Arguments, tt.wantArgs) {
+				t.Errorf("expected arguments %v, got %v", tt.wantArgs, agent.Arguments)
+			}
+		})
+	}
+}
+
+func TestDiscovery_ParseAgentFile_FrontmatterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int
+		wantMsg  string
+	}{
+		{
+			name:     "unquoted colon",
+			content:  "---\nname: reviewer\nmodel: gpt-5: fast\n---\n",
+			wantLine: 3,
+			wantMsg:  "mapping values are not allowed",
+		},
+		{
+			name:     "wrong type",
+			content:  "---\n\nname: reviewer\nkeywords:\n  review: true\n---\n",
+			wantLine: 5,
+			wantMsg:  "keywords: expected a list of strings",
+		},
+		{
+			name:     "mapping as name",
+			content:  "---\nname:\n  first: reviewer\n---\n",
+			wantLine: 3,
+			wantMsg:  "name: cannot unmarshal",
+		},
+		{
+			name:     "duplicate key",
+			content:  "---\nname: reviewer\nname: tester\n---\n",
+			wantLine: 3,
+			wantMsg:  `duplicate key "name"`,
+		},
+		{
+			name:     "invalid timeout",
+			content:  "---\nname: reviewer\ntimeout: soon\n---\n",
+			wantLine: 3,
+			wantMsg:  "timeout: expected a positive duration",
+		},
+		{
+			name:     "negative retries",
+			content:  "---\nname: reviewer\nretries: -1\n---\n",
+			wantLine: 3,
+			wantMsg:  "retries: expected a non-negative integer",
+		},
+		{
+			name:     "unknown backend",
+			content:  "---\nname: reviewer\nbackend: docker\n---\n",
+			wantLine: 3,
+			wantMsg:  "backend: expected cli, sampling or auto",
+		},
+		{
+			name:     "not a mapping",
+			content:  "---\n- reviewer\n---\n",
+			wantLine: 2,
+			wantMsg:  "must be a mapping",
+		},
+		{
+			name:     "append without extends",
+			content:  "---\nname: reviewer\nkeywords:\n  append: [review]\n---\n",
+			wantLine: 3,
+			wantMsg:  "keywords: append requires extends",
+		},
+		{
+			name:     "append_sections without extends",
+			content:  "---\nname: reviewer\nappend_sections: [Rules]\n---\n",
+			wantLine: 3,
+			wantMsg:  "append_sections requires extends",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "agent.md")
+			if err := os.WriteFile(tmpFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+
+			var fe *FrontmatterError
+			if !errors.As(err, &fe) {
+				t.Fatalf("expected *FrontmatterError, got %v", err)
+			}
+			if fe.Line != tt.wantLine {
+				t.Errorf("expected line %d, got %d (%v)", tt.wantLine, fe.Line, err)
+			}
+			if !strings.Contains(fe.Msg, tt.wantMsg) {
+				t.Errorf("expected message containing %q, got %q", tt.wantMsg, fe.Msg)
+			}
+		})
+	}
+}
+
+func TestDiscovery_ParseAgentFile_UnquotedValues(t *testing.T) {
+	// The former line-based parser read each value to the end of its line.
+	tests := []struct {
+		name        string
+		line        string
+		description string
+	}{
+		{"colon", "description: Reviews code: Go and Rust", "Reviews code: Go and Rust"},
+		{"trailing colon", "description: Reviews:", "Reviews:"},
+		{"at sign", "description: @mentions reviewer", "@mentions reviewer"},
+		{"backtick", "description: `go vet` runner", "`go vet` runner"},
+		{"dash", "description: - reviews code", "- reviews code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "---\nname: reviewer\n" + tt.line + "\nkeywords: [review]\n---\n"
+			tmpFile := filepath.Join(t.TempDir(), "reviewer.md")
+			if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := readAgentFile(tmpFile)
+			if err != nil {
+				t.Fatalf("expected the agent to load, got %v", err)
+			}
+			if file.agent.Description != tt.description {
+				t.Errorf("expected description %q, got %q", tt.description, file.agent.Description)
+			}
+			if !reflect.DeepEqual(file.agent.Keywords, []string{"review"}) {
+				t.Errorf("unexpected keywords: %v", file.agent.Keywords)
+			}
+			if len(file.unquoted) != 1 || file.unquoted[0].Line != 3 || !strings.HasPrefix(file.unquoted[0].Msg, "description: quote") {
+				t.Errorf("expected a warning for line 3, got %v", file.unquoted)
+			}
+		})
+	}
+
+	// Frontmatter that stays invalid once the values are quoted still fails.
+	content := "---\nname: @reviewer\ndescription: Reviews code\nkeywords: [review\n---\n"
+	tmpFile := filepath.Join(t.TempDir(), "reviewer.md")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var fe *FrontmatterError
+	if _, err := readAgentFile(tmpFile); !errors.As(err, &fe) {
+		t.Errorf("expected a *FrontmatterError for the unterminated list, got %v", err)
+	}
+}
+
+func TestDiscovery_ParseAgentFile_Settings(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "agent.md")
+	content := `---
+name: reviewer
+timeout: 90s
+retries: 0
+model: gpt-5
+backend: sampling
+max_output_bytes: 65536
+enabled: false
+---
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if agent.Timeout != 90*time.Second {
+		t.Errorf("expected timeout 90s, got %v", agent.Timeout)
+	}
+	if agent.Retries == nil || *agent.Retries != 0 {
+		t.Errorf("expected retries 0, got %v", agent.Retries)
+	}
+	if agent.Model != "gpt-5" || agent.Backend != "sampling" || agent.MaxOutputBytes != 65536 {
+		t.Errorf("unexpected settings: model=%q backend=%q max_output_bytes=%d", agent.Model, agent.Backend, agent.MaxOutputBytes)
+	}
+	if !agent.Disabled {
+		t.Error("expected agent to be disabled")
+	}
+}
+
+func TestDiscovery_ParseAgentFile_Dependencies(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "agent.md")
+	content := `---
+name: test-generator
+requires: [coding-engineer]
+after:
+  - planner
+before: docs-writer, release-notes
+---
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := NewDiscovery(".", zap.NewNop()).parseAgentFile(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(agent.Requires, []string{"coding-engineer"}) {
+		t.Errorf("unexpected requires: %v", agent.Requires)
+	}
+	if !reflect.DeepEqual(agent.After, []string{"planner"}) {
+		t.Errorf("unexpected after: %v", agent.After)
+	}
+	if !reflect.DeepEqual(agent.Before, []string{"docs-writer", "release-notes"}) {
+		t.Errorf("unexpected before: %v", agent.Before)
+	}
+}
+
+func TestDiscovery_Discover_FailureLine(t *testing.T) {
+	tmpDir := t.TempDir()
+	agentsDir := AgentsDir(tmpDir)
+	if err := os.MkdirAll(agentsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: reviewer\nkeywords: {review: true}\n---\n"
+	if err := os.WriteFile(filepath.Join(agentsDir, "reviewer.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	discovery := NewDiscovery(tmpDir, zap.NewNop())
+	if err := discovery.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failures := discovery.Failures()
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failure, got %v", failures)
+	}
+	if failures[0].Line != 3 {
+		t.Errorf("expected failure at line 3, got %d (%s)", failures[0].Line, failures[0].Error)
+	}
+}
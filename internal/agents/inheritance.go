@@ -0,0 +1,219 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// inheritance holds what an agent file declares besides the fields of its
+// agent, which resolving extends needs to tell overrides from inherited
+// values.
+type inheritance struct {
+	declared       map[string]bool // Frontmatter keys set in the file
+	appended       map[string]bool // List keys given as {append: [...]}
+	appendSections []string        // Instruction sections appended to rather than replaced
+}
+
+// inheritedLists maps each list key of the frontmatter that an agent
+// inherits, or appends to with {append: [...]}, to its field.
+var inheritedLists = map[string]func(*Agent) *[]string{
+	"keywords":  func(a *Agent) *[]string { return &a.Keywords },
+	"arguments": func(a *Agent) *[]string { return &a.Arguments },
+	"tools":     func(a *Agent) *[]string { return &a.Tools },
+	"requires":  func(a *Agent) *[]string { return &a.Requires },
+	"after":     func(a *Agent) *[]string { return &a.After },
+	"before":    func(a *Agent) *[]string { return &a.Before },
+}
+
+// extendsResolver merges the agents of a snapshot with the agents they
+// extend. Merged agents are cached, so each base is merged once.
+type extendsResolver struct {
+	snapshot *Snapshot
+	resolved map[string]*Agent
+}
+
+func newExtendsResolver(s *Snapshot) *extendsResolver {
+	return &extendsResolver{snapshot: s, resolved: make(map[string]*Agent)}
+}
+
+// resolve returns agent merged with the agents it extends, recursively, or
+// agent itself when it extends none. It returns an error wrapping a
+// *NotFoundError or *AmbiguousError when a base is not registered, and an
+// *InheritanceCycleError when agents extend each other.
+func (r *extendsResolver) resolve(agent *Agent) (*Agent, error) {
+	return r.walk(agent, nil)
+}
+
+// walk resolves agent for the agents in visiting, each extending the next
+// and the last extending agent.
+func (r *extendsResolver) walk(agent *Agent, visiting []string) (*Agent, error) {
+	if agent.Extends == "" {
+		return agent, nil
+	}
+	if merged := r.resolved[agent.Name]; merged != nil {
+		return merged, nil
+	}
+	if i := slices.Index(visiting, agent.Name); i >= 0 {
+		return nil, &InheritanceCycleError{Path: append(slices.Clone(visiting[i:]), agent.Name)}
+	}
+
+	base, err := r.snapshot.Lookup(r.snapshot.qualify(agent, agent.Extends))
+	if err != nil {
+		return nil, fmt.Errorf("agent %q extends %w", agent.Name, err)
+	}
+	base, err = r.walk(base, append(visiting, agent.Name))
+	if err != nil {
+		// Agents of a cycle report the cycle itself.
+		var cycle *InheritanceCycleError
+		if errors.As(err, &cycle) && slices.Contains(cycle.Path, agent.Name) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("agent %q extends %q: %w", agent.Name, agent.Extends, err)
+	}
+
+	merged := inherit(base, agent)
+	r.resolved[agent.Name] = merged
+	return merged, nil
+}
+
+// inherit returns child merged with its resolved base.
+//
+// Settings and lists the child does not declare are inherited; declared ones
+// override the base, except lists given as {append: [...]}, which add their
+// items to the base's. enabled is never inherited, so a disabled base can
+// serve as a template. Instructions are merged by section; see
+// mergeInstructions.
+func inherit(base, child *Agent) *Agent {
+	merged := *child
+	merged.inheritance = nil
+	declared := child.inheritance.declared
+
+	if !declared["description"] {
+		merged.Description = base.Description
+	}
+	if !declared["timeout"] {
+		merged.Timeout = base.Timeout
+	}
+	if !declared["retries"] {
+		merged.Retries = base.Retries
+	}
+	if !declared["model"] {
+		merged.Model = base.Model
+	}
+	if !declared["backend"] {
+		merged.Backend = base.Backend
+	}
+	if !declared["max_output_bytes"] {
+		merged.MaxOutputBytes = base.MaxOutputBytes
+	}
+
+	for key, field := range inheritedLists {
+		own, inherited := field(child), field(base)
+		switch {
+		case !declared[key]:
+			*field(&merged) = slices.Clone(*inherited)
+		case child.inheritance.appended[key]:
+			list := slices.Clone(*inherited)
+			for _, item := range *own {
+				if !slices.Contains(list, item) {
+					list = append(list, item)
+				}
+			}
+			*field(&merged) = list
+		}
+	}
+	if merged.Keywords == nil {
+		merged.Keywords = []string{}
+	}
+
+	merged.Instructions = mergeInstructions(base.Instructions, child.Instructions, child.inheritance.appendSections)
+	return &merged
+}
+
+// section is a part of Markdown instructions: the text before the first
+// heading, with an empty title, or a heading and the text up to the next.
+type section struct {
+	title string
+	text  string // Heading and body
+	body  string // Body without the heading
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fencePattern   = regexp.MustCompile("^ {0,3}(```|~~~)")
+)
+
+// splitSections splits Markdown instructions at their ATX headings, ignoring
+// lines of fenced code blocks.
+func splitSections(text string) []section {
+	var sections []section
+	var current section
+	var lines []string
+	flush := func() {
+		current.text = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.title != "" || current.text != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+		} else if m := headingPattern.FindStringSubmatch(line); m != nil && fence == "" {
+			flush()
+			current = section{title: strings.TrimSpace(m[1])}
+			lines = []string{line}
+			continue
+		}
+		lines = append(lines, line)
+		if current.title != "" {
+			current.body = strings.TrimSpace(strings.Join(lines[1:], "\n"))
+		}
+	}
+	flush()
+	return sections
+}
+
+// mergeInstructions merges the instructions of a child agent into those of
+// its base. A child section replaces the base section of the same title, or
+// has its body appended to it when the title is in appendSections; other
+// child sections follow the base's. The text before the first heading is a
+// section with an empty title. A child without instructions inherits the
+// base's unchanged.
+func mergeInstructions(base, child string, appendSections []string) string {
+	if strings.TrimSpace(child) == "" {
+		return base
+	}
+
+	merged := splitSections(base)
+	for _, s := range splitSections(child) {
+		i := slices.IndexFunc(merged, func(b section) bool { return b.title == s.title })
+		switch {
+		case i < 0 && s.title == "":
+			merged = slices.Insert(merged, 0, s)
+		case i < 0:
+			merged = append(merged, s)
+		case s.title != "" && slices.Contains(appendSections, s.title):
+			if s.body != "" {
+				merged[i].text += "\n\n" + s.body
+			}
+		default:
+			merged[i] = s
+		}
+	}
+
+	texts := make([]string, len(merged))
+	for i, s := range merged {
+		texts[i] = s.text
+	}
+	return strings.Join(texts, "\n\n")
+}
@@ -0,0 +1,220 @@
+package agents
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestDiscovery_Discover_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	agentsDir := AgentsDir(tmpDir)
+
+	files := map[string]string{
+		"code-reviewer.md": `---
+name: code-reviewer
+description: Reviews code
+keywords: [code-review, quality]
+tools: [read]
+timeout: 5m
+model: gpt-5
+enabled: false
+---
+Review the change.
+
+## Checklist
+- Correctness
+
+## Output
+A list of findings.
+`,
+		"go-reviewer.md": `---
+name: go-reviewer
+extends: code-reviewer
+keywords:
+  append: [golang]
+model: gpt-5-mini
+append_sections: [Checklist]
+---
+## Checklist
+- Error wrapping
+
+## Output
+Findings grouped by file.
+
+## Go
+Run go vet.
+`,
+		"strict-go-reviewer.md": `---
+name: strict-go-reviewer
+description: Reviews Go code strictly
+extends: go-reviewer
+keywords: [strict]
+---
+`,
+		"orphan.md":   "---\nname: orphan\nextends: missing\n---\n",
+		"heir.md":     "---\nname: heir\nextends: orphan\n---\n",
+		"ping.md":     "---\nname: ping\nextends: pong\n---\n",
+		"pong.md":     "---\nname: pong\nextends: ping\n---\n",
+		"pinger.md":   "---\nname: pinger\nextends: ping\n---\n",
+		"security.md": "---\nname: security\nextends: code-reviewer\n---\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(agentsDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(agentsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	discovery := NewDiscovery(tmpDir, zap.NewNop())
+	if err := discovery.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registry := discovery.Registry()
+
+	t.Run("override and append", func(t *testing.T) {
+		agent := registry.Get("go-reviewer")
+		if agent == nil {
+			t.Fatal("expected go-reviewer to be registered")
+		}
+		if agent.Description != "Reviews code" || agent.Model != "gpt-5-mini" || agent.Timeout != 5*time.Minute {
+			t.Errorf("unexpected settings: %q, %q, %v", agent.Description, agent.Model, agent.Timeout)
+		}
+		if !reflect.DeepEqual(agent.Keywords, []string{"code-review", "quality", "golang"}) {
+			t.Errorf("unexpected keywords: %v", agent.Keywords)
+		}
+		if !reflect.DeepEqual(agent.Tools, []string{"read"}) {
+			t.Errorf("unexpected tools: %v", agent.Tools)
+		}
+		if agent.Disabled {
+			t.Error("expected enabled not to be inherited")
+		}
+		if agent.Extends != "code-reviewer" || agent.CopilotAgent() {
+			t.Errorf("expected an extending agent, got extends %q", agent.Extends)
+		}
+
+		want := "Review the change.\n\n## Checklist\n- Correctness\n\n- Error wrapping\n\n## Output\nFindings grouped by file.\n\n## Go\nRun go vet."
+		if agent.Instructions != want {
+			t.Errorf("expected instructions\n%s\ngot\n%s", want, agent.Instructions)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		agent := registry.Get("strict-go-reviewer")
+		if agent == nil {
+			t.Fatal("expected strict-go-reviewer to be registered")
+		}
+		if agent.Description != "Reviews Go code strictly" || agent.Model != "gpt-5-mini" {
+			t.Errorf("unexpected settings: %q, %q", agent.Description, agent.Model)
+		}
+		if !reflect.DeepEqual(agent.Keywords, []string{"strict"}) {
+			t.Errorf("expected keywords to be overridden, got %v", agent.Keywords)
+		}
+		if agent.Instructions != registry.Get("go-reviewer").Instructions {
+			t.Errorf("expected instructions to be inherited, got %q", agent.Instructions)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		failures := make(map[string]string)
+		for _, failure := range discovery.Failures() {
+			failures[filepath.Base(failure.File)] = failure.Error
+		}
+		want := map[string]string{
+			"orphan.md": `agent "orphan" extends agent "missing" not found`,
+			"heir.md":   `agent "heir" extends "orphan": agent "orphan" extends agent "missing" not found`,
+			"ping.md":   "agent inheritance cycle: ping -> pong -> ping",
+			"pong.md":   "agent inheritance cycle: pong -> ping -> pong",
+			"pinger.md": `agent "pinger" extends "ping": agent inheritance cycle: ping -> pong -> ping`,
+		}
+		if !reflect.DeepEqual(failures, want) {
+			t.Errorf("expected failures\n%v\ngot\n%v", want, failures)
+		}
+		for name := range want {
+			if agent := registry.Get(strings.TrimSuffix(name, ".md")); agent != nil {
+				t.Errorf("expected %s to be skipped", agent.Name)
+			}
+		}
+		if got := len(registry.All()); got != 4 {
+			t.Errorf("expected 4 agents, got %d", got)
+		}
+	})
+}
+
+func TestExtendsResolver_Cycle(t *testing.T) {
+	registry := NewRegistry()
+	for _, agent := range []*Agent{
+		{Name: "a", Extends: "b", inheritance: &inheritance{}},
+		{Name: "b", Extends: "a", inheritance: &inheritance{}},
+	} {
+		if err := registry.Add(agent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := newExtendsResolver(registry.Snapshot()).resolve(registry.Get("a"))
+	if !errors.Is(err, ErrInheritanceCycle) {
+		t.Fatalf("expected ErrInheritanceCycle, got %v", err)
+	}
+	var cycle *InheritanceCycleError
+	if !errors.As(err, &cycle) || !reflect.DeepEqual(cycle.Path, []string{"a", "b", "a"}) {
+		t.Errorf("unexpected cycle: %v", err)
+	}
+}
+
+func TestMergeInstructions(t *testing.T) {
+	tests := []struct {
+		name           string
+		base           string
+		child          string
+		appendSections []string
+		want           string
+	}{
+		{
+			name: "empty child inherits",
+			base: "Base.\n\n## Rules\nBe kind.",
+			want: "Base.\n\n## Rules\nBe kind.",
+		},
+		{
+			name:  "preamble and section replaced",
+			base:  "Base.\n\n## Rules\nBe kind.",
+			child: "Child.\n\n## Rules\nBe brief.",
+			want:  "Child.\n\n## Rules\nBe brief.",
+		},
+		{
+			name:  "preamble added before sections",
+			base:  "## Rules\nBe kind.",
+			child: "Child.",
+			want:  "Child.\n\n## Rules\nBe kind.",
+		},
+		{
+			name:           "section appended",
+			base:           "## Rules\nBe kind.",
+			child:          "## Rules\nBe brief.",
+			appendSections: []string{"Rules"},
+			want:           "## Rules\nBe kind.\n\nBe brief.",
+		},
+		{
+			name:  "headings in code blocks",
+			base:  "## Example\n```sh\n# comment\n```",
+			child: "## Example\n~~~\n# other\n~~~",
+			want:  "## Example\n~~~\n# other\n~~~",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeInstructions(tt.base, tt.child, tt.appendSections); got != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
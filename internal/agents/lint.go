@@ -0,0 +1,311 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LintSeverity is the severity of a LintIssue.
+type LintSeverity string
+
+// Severities of lint issues.
+const (
+	LintError   LintSeverity = "error"   // The agent file is skipped by discovery
+	LintWarning LintSeverity = "warning" // The agent loads but is likely a mistake
+)
+
+// Lint rules, reported in LintIssue.Rule.
+const (
+	RuleInvalidFile        = "invalid-file"        // The file cannot be read or its frontmatter is invalid
+	RuleMissingName        = "missing-name"        // The frontmatter declares no name
+	RuleDuplicateName      = "duplicate-name"      // Another file of the search path declares the same name
+	RuleNameMismatch       = "name-mismatch"       // The name differs from the file name
+	RuleEmptyDescription   = "empty-description"   // The description is missing or empty
+	RuleUnknownKey         = "unknown-key"         // The frontmatter has a key discovery ignores
+	RuleUnquotedValue      = "unquoted-value"      // A name or description is not valid YAML without quotes
+	RuleUnreachableKeyword = "unreachable-keyword" // Keywords no prompt produces
+	RuleKeywordOverlap     = "keyword-overlap"     // Every keyword is also a keyword of another agent
+	RuleInvalidExtends     = "invalid-extends"     // The agent extends a missing agent or is part of an inheritance cycle
+)
+
+// LintIssue is a problem found in an agent file.
+type LintIssue struct {
+	File     string       `json:"file"`
+	Line     int          `json:"line,omitempty"`  // 1-based line in the file, 0 when unknown
+	Agent    string       `json:"agent,omitempty"` // Qualified agent name, empty when the file has none
+	Severity LintSeverity `json:"severity"`
+	Rule     string       `json:"rule"`
+	Message  string       `json:"message"`
+}
+
+// LintReport is the result of Discovery.Lint.
+type LintReport struct {
+	Files    int         `json:"files"` // Agent files checked
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+// add appends issue to the report and counts it.
+func (r *LintReport) add(issue LintIssue) {
+	switch issue.Severity {
+	case LintError:
+		r.Errors++
+	case LintWarning:
+		r.Warnings++
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// lintedAgent is an agent file that discovery would register.
+type lintedAgent struct {
+	file  *agentFile
+	path  string
+	agent *Agent // Merged with the agents it extends, nil when extends is invalid
+}
+
+// Lint checks every agent file of the search paths, independently of
+// Discover and without changing the registry.
+//
+// Files that discovery would skip are errors: unreadable files, invalid
+// frontmatter, a missing name, two files of one search path declaring the
+// same agent, and an extends naming a missing agent or forming a cycle.
+// Files that load but are likely mistakes are warnings: a name that differs
+// from the file name, an empty description, unknown frontmatter keys, a name
+// or description that needs quotes, keywords outside vocabulary, and keywords
+// that are all keywords of another enabled agent, which then scores at least
+// as high on every prompt.
+//
+// vocabulary holds the keywords prompts are matched with, normally
+// prompt.Keywords(); the keyword checks are skipped when it is nil.
+func (d *Discovery) Lint(vocabulary []string) (*LintReport, error) {
+	report := &LintReport{Issues: []LintIssue{}}
+
+	registered := make(map[string]*lintedAgent)
+	var linted []*lintedAgent
+	for _, path := range d.SearchPaths() {
+		if _, err := os.Stat(path.Dir); os.IsNotExist(err) {
+			continue
+		}
+
+		// Agents registered from search paths of higher precedence
+		earlier := make(map[string]bool, len(registered))
+		for name := range registered {
+			earlier[name] = true
+		}
+
+		err := walkAgentFiles(path, func(filePath, namespace string) error {
+			report.Files++
+			file, err := readAgentFile(filePath)
+			if err != nil {
+				report.add(fileIssue(filePath, err))
+				return nil
+			}
+			lintFile(report, filePath, file, vocabulary)
+
+			agent := file.agent
+			agent.setNamespace(namespace)
+			if existing := registered[agent.Name]; existing != nil {
+				if !earlier[agent.Name] {
+					report.add(LintIssue{
+						File:     filePath,
+						Line:     file.lines["name"],
+						Agent:    agent.Name,
+						Severity: LintError,
+						Rule:     RuleDuplicateName,
+						Message:  (&DuplicateError{Name: agent.Name, Path: filePath, ExistingPath: existing.path}).Error(),
+					})
+				}
+				return nil
+			}
+			entry := &lintedAgent{file: file, path: filePath}
+			registered[agent.Name] = entry
+			linted = append(linted, entry)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agents directory %s: %w", path.Dir, err)
+		}
+	}
+
+	lintExtends(report, linted)
+	lintOverlaps(report, linted, vocabulary)
+	return report, nil
+}
+
+// lintExtends merges the linted agents with the agents they extend, as
+// discovery does, reporting the agents it cannot merge and merged agents
+// left without a description.
+func lintExtends(report *LintReport, linted []*lintedAgent) {
+	registry := NewRegistry()
+	for _, entry := range linted {
+		entry.agent = entry.file.agent
+		registry.Add(entry.agent)
+	}
+
+	resolver := newExtendsResolver(registry.Snapshot())
+	for _, entry := range linted {
+		if entry.agent.Extends == "" {
+			continue
+		}
+		issue := LintIssue{
+			File:     entry.path,
+			Line:     entry.file.lines["extends"],
+			Agent:    entry.agent.Name,
+			Severity: LintError,
+			Rule:     RuleInvalidExtends,
+		}
+		merged, err := resolver.resolve(entry.agent)
+		if err != nil {
+			issue.Message = err.Error()
+			report.add(issue)
+			entry.agent = nil
+			continue
+		}
+		entry.agent = merged
+		if merged.Description == "" {
+			issue.Line = entry.file.lines["description"]
+			issue.Severity = LintWarning
+			issue.Rule = RuleEmptyDescription
+			issue.Message = fmt.Sprintf("description is empty, including in %q", merged.Extends)
+			report.add(issue)
+		}
+	}
+}
+
+// fileIssue reports an agent file that could not be parsed.
+func fileIssue(filePath string, err error) LintIssue {
+	issue := LintIssue{
+		File:     filePath,
+		Severity: LintError,
+		Rule:     RuleInvalidFile,
+		Message:  err.Error(),
+	}
+	var fe *FrontmatterError
+	if errors.As(err, &fe) {
+		issue.Line = fe.Line
+	}
+	if errors.Is(err, errNameMissing) {
+		issue.Rule = RuleMissingName
+	}
+	return issue
+}
+
+// lintFile reports the warnings of a parsed agent file. The agent has no
+// namespace yet, so its name is the one of the frontmatter.
+func lintFile(report *LintReport, filePath string, file *agentFile, vocabulary []string) {
+	agent := file.agent
+	warn := func(key, rule, msg string) {
+		report.add(LintIssue{
+			File:     filePath,
+			Line:     file.lines[key],
+			Agent:    agent.Name,
+			Severity: LintWarning,
+			Rule:     rule,
+			Message:  msg,
+		})
+	}
+
+	if _, stem, _ := fileFormat(filepath.Base(filePath)); stem != agent.Name {
+		warn("name", RuleNameMismatch, fmt.Sprintf("name %q does not match the file name %q", agent.Name, stem))
+	}
+	if agent.Description == "" && agent.Extends == "" {
+		warn("description", RuleEmptyDescription, "description is empty")
+	}
+	for _, warning := range file.unquoted {
+		report.add(LintIssue{
+			File:     filePath,
+			Line:     warning.Line,
+			Agent:    agent.Name,
+			Severity: LintWarning,
+			Rule:     RuleUnquotedValue,
+			Message:  warning.Msg,
+		})
+	}
+	for _, warning := range file.warnings {
+		report.add(LintIssue{
+			File:     filePath,
+			Line:     warning.Line,
+			Agent:    agent.Name,
+			Severity: LintWarning,
+			Rule:     RuleUnknownKey,
+			Message:  warning.Msg,
+		})
+	}
+	if vocabulary != nil {
+		var unreachable []string
+		for _, kw := range agent.Keywords {
+			if !slices.Contains(vocabulary, kw) {
+				unreachable = append(unreachable, kw)
+			}
+		}
+		if len(unreachable) > 0 {
+			warn("keywords", RuleUnreachableKeyword, fmt.Sprintf("keywords %s are never extracted from a prompt, so they never match", quoteList(unreachable)))
+		}
+	}
+}
+
+// lintOverlaps reports enabled agents whose matching keywords, including
+// inherited ones, are all matching keywords of another enabled agent. Agents
+// with the same keywords are reported once, on the later one.
+func lintOverlaps(report *LintReport, linted []*lintedAgent, vocabulary []string) {
+	keywords := make([][]string, len(linted))
+	for i, entry := range linted {
+		if entry.agent == nil || entry.agent.Disabled {
+			continue
+		}
+		for _, kw := range entry.agent.Keywords {
+			if (vocabulary == nil || slices.Contains(vocabulary, kw)) && !slices.Contains(keywords[i], kw) {
+				keywords[i] = append(keywords[i], kw)
+			}
+		}
+	}
+
+	for i, entry := range linted {
+		if len(keywords[i]) == 0 {
+			continue
+		}
+		for j, other := range linted {
+			if i == j || !subset(keywords[i], keywords[j]) {
+				continue
+			}
+			if j > i && subset(keywords[j], keywords[i]) {
+				continue // Same keywords: reported on other
+			}
+			report.add(LintIssue{
+				File:     entry.path,
+				Line:     entry.file.lines["keywords"],
+				Agent:    entry.agent.Name,
+				Severity: LintWarning,
+				Rule:     RuleKeywordOverlap,
+				Message: fmt.Sprintf("keywords %s are all keywords of %q, which scores at least as high on every prompt",
+					quoteList(keywords[i]), other.agent.Name),
+			})
+			break
+		}
+	}
+}
+
+// quoteList formats items as a comma-separated list of quoted strings.
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// subset reports whether every item of a is in b.
+func subset(a, b []string) bool {
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDiscovery_Lint(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := AgentsDir(tmpDir)
+	sharedDir := filepath.Join(tmpDir, "shared")
+
+	files := map[string]string{
+		filepath.Join(repoDir, "code-reviewer.md"): "---\nname: code-reviewer\ndescription: Reviews code\nkeywords: [code-review, quality]\n---\n",
+		filepath.Join(repoDir, "quality-bot.md"):   "---\nname: quality-bot\ndescription: Checks quality\nkeywords: [quality, style]\n---\n",
+		filepath.Join(repoDir, "tester.md"):        "---\nname: tester\ndescription: Disabled\nkeywords: [quality]\nenabled: false\n---\n",
+		filepath.Join(repoDir, "docs.md"):          "---\nname: documentation-writer\nkeywords: [docs, golang]\ntool: [read]\n---\n",
+		filepath.Join(repoDir, "broken.md"):        "---\nname: broken\ndescription: Broken\nretries: -1\n---\n",
+		filepath.Join(repoDir, "nameless.md"):      "---\ndescription: No name\n---\n",
+		filepath.Join(repoDir, "copy.md"):          "---\nname: code-reviewer\ndescription: Copy\n---\n",
+		filepath.Join(repoDir, "security.md"):      "---\nname: security\nextends: code-reviewer\nkeywords: [testing]\n---\n",
+		filepath.Join(repoDir, "legacy.md"):        "---\nname: legacy\ndescription: Reviews code: Go\n---\n",
+		filepath.Join(repoDir, "orphan.md"):        "---\nname: orphan\nextends: missing\n---\n",
+		filepath.Join(sharedDir, "tester.md"):      "---\nname: tester\ndescription: Shared\nkeywords: [testing]\n---\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		vocabulary []string
+		want       []string // file:rule, in report order
+		errors     int
+	}{
+		{
+			name:       "with vocabulary",
+			vocabulary: []string{"code-review", "docs", "quality", "testing"},
+			want: []string{
+				"broken.md:invalid-file",
+				"copy.md:name-mismatch",
+				"copy.md:duplicate-name",
+				"docs.md:name-mismatch",
+				"docs.md:empty-description",
+				"docs.md:unknown-key",
+				"docs.md:unreachable-keyword",
+				"legacy.md:unquoted-value",
+				"nameless.md:missing-name",
+				"quality-bot.md:unreachable-keyword",
+				"orphan.md:invalid-extends",
+				"quality-bot.md:keyword-overlap",
+			},
+			errors: 4,
+		},
+		{
+			// Without a vocabulary, style keeps quality-bot apart.
+			name: "without vocabulary",
+			want: []string{
+				"broken.md:invalid-file",
+				"copy.md:name-mismatch",
+				"copy.md:duplicate-name",
+				"docs.md:name-mismatch",
+				"docs.md:empty-description",
+				"docs.md:unknown-key",
+				"legacy.md:unquoted-value",
+				"nameless.md:missing-name",
+				"orphan.md:invalid-extends",
+			},
+			errors: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery := NewDiscovery(tmpDir, zap.NewNop())
+			discovery.SetSearchPaths([]SearchPath{{Dir: sharedDir, Source: SourceShared}})
+
+			report, err := discovery.Lint(tt.vocabulary)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, issue := range report.Issues {
+				got = append(got, filepath.Base(issue.File)+":"+issue.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected issues\n%v\ngot\n%v", tt.want, got)
+			}
+			if report.Files != 11 || report.Errors != tt.errors || report.Warnings != len(tt.want)-tt.errors {
+				t.Errorf("unexpected counts: %d files, %d errors, %d warnings", report.Files, report.Errors, report.Warnings)
+			}
+			if len(discovery.Registry().All()) != 0 {
+				t.Error("expected Lint to leave the registry empty")
+			}
+		})
+	}
+
+	// Issues point at the offending line.
+	discovery := NewDiscovery(tmpDir, zap.NewNop())
+	report, err := discovery.Lint(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := make(map[string]int)
+	for _, issue := range report.Issues {
+		lines[filepath.Base(issue.File)+":"+issue.Rule] = issue.Line
+	}
+	want := map[string]int{
+		"broken.md:invalid-file":    4,
+		"copy.md:duplicate-name":    2,
+		"docs.md:empty-description": 0,
+		"docs.md:unknown-key":       4,
+		"orphan.md:invalid-extends": 3,
+		"legacy.md:unquoted-value":  3,
+	}
+	for key, line := range want {
+		if lines[key] != line {
+			t.Errorf("%s: expected line %d, got %d", key, line, lines[key])
+		}
+	}
+}
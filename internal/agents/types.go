@@ -1,58 +1,298 @@
 package agents
 
-import "fmt"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // Agent represents a discovered agent with metadata.
 type Agent struct {
-	Name        string
-	Description string
-	Keywords    []string
+	Name        string   `json:"name"`                // Qualified name, e.g. security/secret-scanner
+	Namespace   string   `json:"namespace,omitempty"` // Subdirectory of the agents directory, e.g. security
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+	Arguments   []string `json:"arguments,omitempty"` // Named inputs the agent expects, e.g. file, focus
+	Path        string   `json:"path,omitempty"`      // Source file, empty for agents not loaded from disk
+	Source      string   `json:"source,omitempty"`    // Search path the file was found in: repo, user or shared
+	Format      string   `json:"format,omitempty"`    // File format: copilot-os, custom-agent, chatmode or prompt
+	Tools       []string `json:"tools,omitempty"`     // Tools granted by a Copilot custom agent, chat mode or prompt file
+	// Instructions is the Markdown body following the frontmatter.
+	Instructions string `json:"instructions,omitempty"`
+
+	// Ordering constraints from the frontmatter; see Registry.Resolve.
+	Requires []string `json:"requires,omitempty"` // Agents pulled into the chain and run before this one
+	After    []string `json:"after,omitempty"`    // Agents that run before this one when in the same chain
+	Before   []string `json:"before,omitempty"`   // Agents that run after this one when in the same chain
+
+	// Execution settings from the frontmatter. Zero values use the server's
+	// defaults.
+	Timeout        time.Duration `json:"timeout,omitempty"`          // Per-invocation timeout
+	Retries        *int          `json:"retries,omitempty"`          // Retries after a failed run
+	Model          string        `json:"model,omitempty"`            // Model requested from the backend
+	Backend        string        `json:"backend,omitempty"`          // cli, sampling or auto
+	MaxOutputBytes int           `json:"max_output_bytes,omitempty"` // Output kept per invocation
+	Disabled       bool          `json:"disabled,omitempty"`         // Set by enabled: false; never selected or run
+
+	// Extends names the agent this one inherits from. Discovery merges the
+	// two, so the fields above hold the merged values.
+	Extends     string       `json:"extends,omitempty"`
+	inheritance *inheritance // Set until the agent is merged with its base
+}
+
+// CopilotAgent reports whether the Copilot CLI can load the agent by name
+// as a custom agent. The CLI only reads the repository's agents directory,
+// so agents from the user and shared search paths, and agents not loaded
+// from disk, are not custom agents. Neither are namespaced agents, whose
+// qualified names the CLI does not resolve, chat modes and prompt files,
+// and the file of an agent that extends another holds only part of it. The
+// instructions of all these must be passed with the prompt.
+func (a *Agent) CopilotAgent() bool {
+	return a.Source == SourceRepo && a.Namespace == "" &&
+		a.Format != FormatChatMode && a.Format != FormatPrompt && a.Extends == ""
+}
+
+// setNamespace places agent in namespace, prefixing its name.
+func (a *Agent) setNamespace(namespace string) {
+	if namespace != "" {
+		a.Namespace = namespace
+		a.Name = namespace + "/" + a.Name
+	}
 }
 
-// Registry holds discovered agents.
+// Registry holds discovered agents. It is safe for concurrent use: every
+// change publishes a new immutable Snapshot with a higher version, so readers
+// never observe a partial update. Read methods on Registry use the current
+// snapshot; callers that make several reads, such as an orchestration run,
+// should take one Snapshot and read from it.
 type Registry struct {
-	agents map[string]*Agent
-	order  []string // Maintain discovery order
+	mu       sync.Mutex // Serializes changes
+	snapshot atomic.Pointer[Snapshot]
 }
 
-// NewRegistry creates a new empty registry.
+// Snapshot is an immutable view of a Registry at one version.
+type Snapshot struct {
+	version uint64
+	agents  map[string]*Agent
+	order   []string // Maintain discovery order
+}
+
+// NewRegistry creates a new empty registry at version 0.
 func NewRegistry() *Registry {
-	return &Registry{
+	r := &Registry{}
+	r.snapshot.Store(&Snapshot{
 		agents: make(map[string]*Agent),
 		order:  []string{},
+	})
+	return r
+}
+
+// Snapshot returns the current contents of the registry. Later changes to
+// the registry do not affect the returned snapshot.
+func (r *Registry) Snapshot() *Snapshot {
+	return r.snapshot.Load()
+}
+
+// Version returns the version of the current snapshot. It starts at 0 and
+// increases by one with every change.
+func (r *Registry) Version() uint64 {
+	return r.Snapshot().version
+}
+
+// update applies change to a copy of the current snapshot and publishes it
+// with the next version if change reports a modification.
+func (r *Registry) update(change func(next *Snapshot) (bool, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.snapshot.Load()
+	next := &Snapshot{
+		version: current.version + 1,
+		agents:  maps.Clone(current.agents),
+		order:   slices.Clone(current.order),
+	}
+	changed, err := change(next)
+	if err != nil || !changed {
+		return err
 	}
+	r.snapshot.Store(next)
+	return nil
 }
 
-// Add adds an agent to the registry.
+// Add adds an agent to the registry. It returns a *DuplicateError naming
+// both agent files when an agent of the same name is registered.
 func (r *Registry) Add(agent *Agent) error {
 	if agent.Name == "" {
 		return fmt.Errorf("agent name cannot be empty")
 	}
-	if _, exists := r.agents[agent.Name]; exists {
-		return fmt.Errorf("agent %q already registered", agent.Name)
+	return r.update(func(next *Snapshot) (bool, error) {
+		if existing, exists := next.agents[agent.Name]; exists {
+			return false, &DuplicateError{Name: agent.Name, Path: agent.Path, ExistingPath: existing.Path}
+		}
+		next.agents[agent.Name] = agent
+		next.order = append(next.order, agent.Name)
+		return true, nil
+	})
+}
+
+// Replace registers agent in place of the agent of the same name, keeping
+// its position, or adds it when no such agent is registered.
+func (r *Registry) Replace(agent *Agent) error {
+	if agent.Name == "" {
+		return fmt.Errorf("agent name cannot be empty")
 	}
-	r.agents[agent.Name] = agent
-	r.order = append(r.order, agent.Name)
-	return nil
+	return r.update(func(next *Snapshot) (bool, error) {
+		if _, exists := next.agents[agent.Name]; !exists {
+			next.order = append(next.order, agent.Name)
+		}
+		next.agents[agent.Name] = agent
+		return true, nil
+	})
+}
+
+// Remove removes the agent with the given qualified name and reports
+// whether it was registered.
+func (r *Registry) Remove(name string) bool {
+	removed := false
+	r.update(func(next *Snapshot) (bool, error) {
+		if _, exists := next.agents[name]; !exists {
+			return false, nil
+		}
+		delete(next.agents, name)
+		next.order = slices.DeleteFunc(next.order, func(n string) bool { return n == name })
+		removed = true
+		return true, nil
+	})
+	return removed
+}
+
+// ReplaceAll replaces every agent of the registry with agents, in their
+// order, as a single change. It fails without changing the registry when
+// agents holds an unnamed agent or two agents of the same name.
+func (r *Registry) ReplaceAll(agents []*Agent) error {
+	return r.update(func(next *Snapshot) (bool, error) {
+		next.agents = make(map[string]*Agent, len(agents))
+		next.order = make([]string, 0, len(agents))
+		for _, agent := range agents {
+			if agent.Name == "" {
+				return false, fmt.Errorf("agent name cannot be empty")
+			}
+			if existing, exists := next.agents[agent.Name]; exists {
+				return false, &DuplicateError{Name: agent.Name, Path: agent.Path, ExistingPath: existing.Path}
+			}
+			next.agents[agent.Name] = agent
+			next.order = append(next.order, agent.Name)
+		}
+		return true, nil
+	})
 }
 
-// Get retrieves an agent by name.
+// Get retrieves an agent by name from the current snapshot; see
+// Snapshot.Get.
 func (r *Registry) Get(name string) *Agent {
-	return r.agents[name]
+	return r.Snapshot().Get(name)
+}
+
+// Lookup retrieves an agent by name from the current snapshot; see
+// Snapshot.Lookup.
+func (r *Registry) Lookup(name string) (*Agent, error) {
+	return r.Snapshot().Lookup(name)
+}
+
+// InNamespace returns the agents of a namespace in the current snapshot;
+// see Snapshot.InNamespace.
+func (r *Registry) InNamespace(namespace string) []*Agent {
+	return r.Snapshot().InNamespace(namespace)
 }
 
 // All returns all registered agents.
 func (r *Registry) All() []*Agent {
-	agents := make([]*Agent, len(r.order))
-	for i, name := range r.order {
-		agents[i] = r.agents[name]
+	return r.Snapshot().All()
+}
+
+// MatchKeywords finds agents matching the given keywords in the current
+// snapshot; see Snapshot.MatchKeywords.
+func (r *Registry) MatchKeywords(keywords []string) []*Agent {
+	return r.Snapshot().MatchKeywords(keywords)
+}
+
+// Version returns the version of the registry the snapshot was taken at.
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// Get retrieves an agent by name. A name without a namespace, such as
+// secret-scanner, also finds a namespaced agent like security/secret-scanner
+// when it is the only agent of that name. Get returns nil for unknown and
+// ambiguous names.
+func (s *Snapshot) Get(name string) *Agent {
+	agent, _ := s.Lookup(name)
+	return agent
+}
+
+// Lookup retrieves an agent by name like Get, returning a *NotFoundError
+// when the agent is not registered and an *AmbiguousError when a name
+// without a namespace matches agents in several namespaces.
+func (s *Snapshot) Lookup(name string) (*Agent, error) {
+	if agent := s.agents[name]; agent != nil {
+		return agent, nil
+	}
+	if strings.Contains(name, "/") {
+		return nil, &NotFoundError{Name: name}
+	}
+
+	var matches []*Agent
+	for _, qualified := range s.order {
+		if strings.HasSuffix(qualified, "/"+name) {
+			matches = append(matches, s.agents[qualified])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, &NotFoundError{Name: name}
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, agent := range matches {
+			names[i] = agent.Name
+		}
+		return nil, &AmbiguousError{Name: name, Matches: names}
+	}
+}
+
+// InNamespace returns the agents of namespace and of the namespaces nested
+// in it, in discovery order. An empty namespace returns all agents.
+func (s *Snapshot) InNamespace(namespace string) []*Agent {
+	namespace = strings.Trim(namespace, "/")
+	if namespace == "" {
+		return s.All()
+	}
+	agents := make([]*Agent, 0)
+	for _, agent := range s.All() {
+		if agent.Namespace == namespace || strings.HasPrefix(agent.Namespace, namespace+"/") {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// All returns all agents of the snapshot in discovery order.
+func (s *Snapshot) All() []*Agent {
+	agents := make([]*Agent, len(s.order))
+	for i, name := range s.order {
+		agents[i] = s.agents[name]
 	}
 	return agents
 }
 
 // MatchKeywords finds agents matching the given keywords.
 // Returns agents ranked by match score (highest first).
-func (r *Registry) MatchKeywords(keywords []string) []*Agent {
+func (s *Snapshot) MatchKeywords(keywords []string) []*Agent {
 	type scored struct {
 		agent *Agent
 		score float64
@@ -64,9 +304,12 @@ func (r *Registry) MatchKeywords(keywords []string) []*Agent {
 		keywordSet[kw] = true
 	}
 
-	// Score each agent
+	// Score each agent; disabled agents are never matched
 	scores := make([]scored, 0)
-	for _, agent := range r.All() {
+	for _, agent := range s.All() {
+		if agent.Disabled {
+			continue
+		}
 		score := calculateMatchScore(agent.Keywords, keywordSet)
 		if score > 0 {
 			scores = append(scores, scored{agent, score})
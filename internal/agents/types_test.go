@@ -1,6 +1,10 @@
 package agents
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -34,6 +38,202 @@ func TestRegistry_Get_NotFound(t *testing.T) {
 	}
 }
 
+func TestRegistry_Lookup(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Agent{Name: "code-reviewer"})
+
+	agent, err := registry.Lookup("code-reviewer")
+	if err != nil || agent == nil {
+		t.Fatalf("expected agent, got %v, %v", agent, err)
+	}
+
+	_, err = registry.Lookup("nonexistent")
+	if !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "nonexistent" {
+		t.Errorf("expected *NotFoundError for nonexistent, got %v", err)
+	}
+}
+
+func TestRegistry_Lookup_Namespaced(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Agent{Name: "security/secret-scanner", Namespace: "security"})
+	registry.Add(&Agent{Name: "security/reviewer", Namespace: "security"})
+	registry.Add(&Agent{Name: "frontend/reviewer", Namespace: "frontend"})
+	registry.Add(&Agent{Name: "code-reviewer"})
+
+	tests := []struct {
+		name    string
+		lookup  string
+		want    string
+		wantErr error
+	}{
+		{"qualified name", "security/reviewer", "security/reviewer", nil},
+		{"unique short name", "secret-scanner", "security/secret-scanner", nil},
+		{"top-level agent", "code-reviewer", "code-reviewer", nil},
+		{"ambiguous short name", "reviewer", "", ErrAgentNotFound},
+		{"unknown namespace", "backend/secret-scanner", "", ErrAgentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent, err := registry.Lookup(tt.lookup)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if agent.Name != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, agent.Name)
+			}
+		})
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := registry.Lookup("reviewer"); !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("expected *AmbiguousError with 2 matches, got %v", err)
+	}
+}
+
+func TestRegistry_InNamespace(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Agent{Name: "security/secret-scanner", Namespace: "security"})
+	registry.Add(&Agent{Name: "security/web/xss-checker", Namespace: "security/web"})
+	registry.Add(&Agent{Name: "securityish/linter", Namespace: "securityish"})
+	registry.Add(&Agent{Name: "code-reviewer"})
+
+	tests := []struct {
+		namespace string
+		want      int
+	}{
+		{"", 4},
+		{"security", 2},
+		{"security/web", 1},
+		{"frontend", 0},
+	}
+
+	for _, tt := range tests {
+		if got := registry.InNamespace(tt.namespace); len(got) != tt.want {
+			t.Errorf("InNamespace(%q): expected %d agents, got %d", tt.namespace, tt.want, len(got))
+		}
+	}
+}
+
+func TestRegistry_Changes(t *testing.T) {
+	registry := NewRegistry()
+	if registry.Version() != 0 {
+		t.Fatalf("expected version 0, got %d", registry.Version())
+	}
+
+	registry.Add(&Agent{Name: "code-reviewer", Description: "v1"})
+	registry.Add(&Agent{Name: "test-generator"})
+	initial := registry.Snapshot()
+
+	steps := []struct {
+		name    string
+		change  func() error
+		want    []string
+		version uint64
+	}{
+		{"replace keeps position", func() error {
+			return registry.Replace(&Agent{Name: "code-reviewer", Description: "v2"})
+		}, []string{"code-reviewer", "test-generator"}, 3},
+		{"replace adds", func() error {
+			return registry.Replace(&Agent{Name: "docs-writer"})
+		}, []string{"code-reviewer", "test-generator", "docs-writer"}, 4},
+		{"remove", func() error {
+			if !registry.Remove("test-generator") {
+				return errors.New("expected test-generator to be removed")
+			}
+			return nil
+		}, []string{"code-reviewer", "docs-writer"}, 5},
+		{"remove unknown leaves version", func() error {
+			if registry.Remove("test-generator") {
+				return errors.New("expected nothing to be removed")
+			}
+			return nil
+		}, []string{"code-reviewer", "docs-writer"}, 5},
+		{"failed add leaves version", func() error {
+			if registry.Add(&Agent{Name: "docs-writer"}) == nil {
+				return errors.New("expected a duplicate error")
+			}
+			return nil
+		}, []string{"code-reviewer", "docs-writer"}, 5},
+		{"replace all", func() error {
+			return registry.ReplaceAll([]*Agent{{Name: "linter"}, {Name: "code-reviewer"}})
+		}, []string{"linter", "code-reviewer"}, 6},
+		{"replace all with duplicates fails", func() error {
+			if registry.ReplaceAll([]*Agent{{Name: "a"}, {Name: "a"}}) == nil {
+				return errors.New("expected a duplicate error")
+			}
+			return nil
+		}, []string{"linter", "code-reviewer"}, 6},
+	}
+
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		var got []string
+		for _, agent := range registry.All() {
+			got = append(got, agent.Name)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: expected %v, got %v", step.name, step.want, got)
+		}
+		if registry.Version() != step.version {
+			t.Errorf("%s: expected version %d, got %d", step.name, step.version, registry.Version())
+		}
+	}
+
+	// Snapshots are not affected by later changes.
+	if initial.Version() != 2 || len(initial.All()) != 2 || initial.Get("code-reviewer").Description != "v1" {
+		t.Errorf("expected the initial snapshot to be unchanged, got version %d with %v", initial.Version(), initial.All())
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	registry := NewRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("agent-%d-%d", i, j)
+				registry.Add(&Agent{Name: name, Keywords: []string{"review"}})
+				registry.Replace(&Agent{Name: name, Keywords: []string{"review", "quality"}})
+				if j%2 == 0 {
+					registry.Remove(name)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				snapshot := registry.Snapshot()
+				if len(snapshot.All()) != len(snapshot.order) {
+					t.Error("inconsistent snapshot")
+				}
+				registry.MatchKeywords([]string{"review"})
+				registry.Get("agent-0-1")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(registry.All()); got != 8*25 {
+		t.Errorf("expected %d agents, got %d", 8*25, got)
+	}
+	if got := registry.Version(); got != 8*(50*2+25) {
+		t.Errorf("expected version %d, got %d", 8*(50*2+25), got)
+	}
+}
+
 func TestRegistry_All(t *testing.T) {
 	registry := NewRegistry()
 
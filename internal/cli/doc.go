@@ -41,10 +41,28 @@
 //
 // # Retry Logic
 //
-// The invoker supports automatic retries for transient failures:
+// The invoker retries runs that exited with a failure:
 //   - Default retries: 1 (total of 2 attempts)
-//   - Retries on: network errors, temporary CLI failures
-//   - No retry on: invalid agent names, syntax errors, user cancellation
+//   - Retries on: non-zero exit of the copilot process
+//   - No retry on: timeouts, missing CLI, user cancellation
+//
+// # Per-Invocation Options
+//
+// WithInvocationOptions attaches InvocationOptions to a context to override
+// the invoker's defaults for one invocation: the timeout, the number of
+// retries, the model passed as --model, and a limit on the bytes of output
+// kept. Output beyond the limit is dropped and the result is marked
+// Truncated. The orchestrator sets these from each agent's frontmatter.
+// It also sets Dir to the root of the agent's repository, so the CLI loads
+// agent files from, and works in, that repository rather than the server's
+// working directory.
+//
+// Copilot chat modes and prompt files are not custom agents, and the CLI
+// only reads the repository's agents directory and does not resolve
+// namespaces, so it cannot load these, namespaced agents or agents from the
+// user and shared search paths with --agent. For these the orchestrator sets
+// InvocationOptions.Instructions, and the CLI runs without --agent, with the
+// instructions ahead of the prompt.
 //
 // # Result Structure
 //
@@ -80,12 +98,12 @@
 // The package distinguishes between different error types:
 //
 // 1. CLI Execution Errors:
-//   - Command not found (copilot CLI not installed)
+//   - Command not found (copilot CLI not installed), wraps ErrCLINotAvailable
 //   - Permission denied
 //   - System errors
 //
 // 2. Timeout Errors:
-//   - Context deadline exceeded
+//   - Context deadline exceeded, wraps ErrExecutionTimeout
 //   - Operation took too long
 //
 // 3. Agent Errors:
@@ -93,14 +111,24 @@
 //   - Agent execution failed
 //   - Agent returned error output
 //
-// All errors are wrapped with context for easier debugging.
+// CLI execution and timeout errors are returned as Go errors alongside a
+// populated InvocationResult; match them with errors.Is:
+//
+//	result, err := invoker.InvokeAgent(ctx, "code-reviewer", prompt)
+//	if errors.Is(err, cli.ErrCLINotAvailable) {
+//	    // prompt the user to install the Copilot CLI
+//	}
+//
+// Agent errors are reported only on the result (Success=false, Error set).
 //
 // # Context Cancellation
 //
 // The package respects context cancellation throughout:
 //   - Parent context cancellation stops CLI execution
 //   - Timeout contexts are created per-invocation
-//   - Graceful cleanup on cancellation
+//   - On Unix the CLI runs in its own process group, and cancellation kills
+//     the whole group so no helper processes are left behind
+//   - Cancelled invocations return a result with Cancelled set
 //
 // # Performance Considerations
 //
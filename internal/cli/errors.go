@@ -0,0 +1,12 @@
+package cli
+
+import "errors"
+
+var (
+	// ErrCLINotAvailable is returned when the copilot binary cannot be found
+	// or started.
+	ErrCLINotAvailable = errors.New("copilot CLI not available")
+
+	// ErrExecutionTimeout is returned when an invocation exceeds its timeout.
+	ErrExecutionTimeout = errors.New("agent execution timed out")
+)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -12,6 +13,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// processWaitDelay bounds how long a cancelled invocation waits for the
+// process's output pipes to close before giving up on them.
+const processWaitDelay = 2 * time.Second
+
 // InvocationResult holds the result of a CLI invocation.
 type InvocationResult struct {
 	Agent     string          `json:"agent"`
@@ -21,6 +26,8 @@ type InvocationResult struct {
 	ExitCode  int             `json:"exit_code"`
 	Duration  time.Duration   `json:"duration_ms"`
 	Timestamp time.Time       `json:"timestamp"`
+	Cancelled bool            `json:"cancelled,omitempty"` // Invocation was aborted by context cancellation
+	Truncated bool            `json:"truncated,omitempty"` // Output was cut off at the agent's max_output_bytes
 }
 
 // Invoker handles invocation of Copilot CLI agents.
@@ -39,6 +46,13 @@ func NewInvoker(timeout time.Duration, logger *zap.Logger) *Invoker {
 	}
 }
 
+// LoadsInstructions reports that the Copilot CLI loads the agent file named
+// by --agent itself, so the orchestrator does not repeat its instructions in
+// the prompt.
+func (i *Invoker) LoadsInstructions() bool {
+	return true
+}
+
 // InvokeAgent invokes a specific agent with the given prompt.
 //
 // This function executes the GitHub Copilot CLI with the specified agent and prompt,
@@ -46,11 +60,15 @@ func NewInvoker(timeout time.Duration, logger *zap.Logger) *Invoker {
 //
 // Execution Flow:
 //  1. Create timeout context (if not already present)
-//  2. Build CLI command: copilot --agent <name> --prompt "<text>"
+//  2. Build CLI command: copilot --agent <name> --prompt "<text>" [--model <model>]
 //  3. Execute command with context
-//  4. Capture stdout/stderr
+//  4. Capture stdout (up to the output limit) and stderr
 //  5. Wait for completion or timeout
 //  6. Parse and return structured result
+//  7. Retry runs that exited non-zero, up to the retry count
+//
+// The timeout, retry count, model and output limit can be overridden per
+// invocation with WithInvocationOptions.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -58,43 +76,82 @@ func NewInvoker(timeout time.Duration, logger *zap.Logger) *Invoker {
 //   - prompt: The prompt/task to send to the agent
 //
 // Returns:
-//   - InvocationResult: Structured result with output, status, timing
-//   - error: Non-nil if CLI execution failed
+//   - InvocationResult: Structured result with output, status, timing; always
+//     non-nil, even when an error is returned
+//   - error: Non-nil if the CLI could not run the agent to completion
 //
 // Error Conditions:
 //   - Command not found: copilot CLI not installed or not in PATH
-//   - Timeout: Operation exceeded deadline
-//   - Context cancelled: Parent context was cancelled
+//     (wraps ErrCLINotAvailable)
+//   - Timeout: Operation exceeded deadline (wraps ErrExecutionTimeout)
+//   - Context cancelled: Parent context was cancelled; the CLI process (and
+//     its process group on Unix) is killed and Cancelled is set on the result
 //   - Exit code > 0: Agent execution failed
 //
 // Note: An agent returning an error (exit code > 0) is still returned as an
 // InvocationResult with Success=false and Error populated. This is not considered
 // a Go error - only CLI execution failures return Go errors.
 func (i *Invoker) InvokeAgent(ctx context.Context, agentName, prompt string) (*InvocationResult, error) {
+	opts := InvocationOptionsFrom(ctx)
+	retries := i.retries
+	if opts.Retries != nil {
+		retries = *opts.Retries
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := i.invokeOnce(ctx, agentName, prompt, opts)
+
+		// Only runs that exited non-zero are retried; timeouts, cancellation
+		// and a missing CLI would fail the same way again.
+		if err != nil || result.Success || result.Cancelled || attempt > retries || ctx.Err() != nil {
+			return result, err
+		}
+		i.logger.Info("retrying agent invocation",
+			zap.String("agent", agentName),
+			zap.Int("attempt", attempt+1),
+			zap.Int("retries", retries),
+		)
+	}
+}
+
+// invokeOnce runs the Copilot CLI once for InvokeAgent.
+func (i *Invoker) invokeOnce(ctx context.Context, agentName, prompt string, opts InvocationOptions) (*InvocationResult, error) {
 	start := time.Now()
 	result := &InvocationResult{
 		Agent:     agentName,
 		Timestamp: start,
 	}
 
-	// Create context with timeout if not already set
-	if _, ok := ctx.Deadline(); !ok {
+	// Create context with timeout if not already set. A per-agent timeout
+	// always applies.
+	timeout := i.timeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	} else if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 		defer cancel()
 	}
 
 	// Prepare command
-	cmd := exec.CommandContext(
-		ctx,
-		"copilot",
-		"--agent="+agentName,
-		"--prompt="+prompt,
-	)
+	args := []string{"--agent=" + agentName, "--prompt=" + prompt}
+	if opts.Instructions != "" {
+		args = []string{"--prompt=" + opts.Instructions + "\n\n" + prompt}
+	}
+	if opts.Model != "" {
+		args = append(args, "--model="+opts.Model)
+	}
+	cmd := exec.CommandContext(ctx, "copilot", args...)
+	cmd.Dir = opts.Dir
+	configureProcessCleanup(cmd)
 
 	// Capture output
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+	stdout := &limitWriter{max: opts.MaxOutputBytes}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
 	cmd.Stderr = &stderr
 
 	// Run command
@@ -112,6 +169,13 @@ func (i *Invoker) InvokeAgent(ctx context.Context, agentName, prompt string) (*I
 
 	// Handle output
 	stdoutStr := stdout.String()
+	if stdout.truncated {
+		result.Truncated = true
+		i.logger.Warn("agent output truncated",
+			zap.String("agent", agentName),
+			zap.Int("max_output_bytes", opts.MaxOutputBytes),
+		)
+	}
 	if stdoutStr != "" {
 		// Try to parse as JSON
 		var jsonOutput json.RawMessage
@@ -119,16 +183,25 @@ func (i *Invoker) InvokeAgent(ctx context.Context, agentName, prompt string) (*I
 			result.Output = jsonOutput
 			result.Success = true
 		} else {
-			// If not JSON, wrap in string output
-			result.Output = json.RawMessage([]byte(`"` + strings.TrimSpace(stdoutStr) + `"`))
+			// If not JSON (or cut off by the output limit), wrap in string output
+			encoded, _ := json.Marshal(strings.TrimSpace(stdoutStr))
+			result.Output = json.RawMessage(encoded)
 			result.Success = true
 		}
 	}
 
 	// Handle errors
+	var invokeErr error
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			result.Error = fmt.Sprintf("agent invocation timed out after %v", i.timeout)
+			result.Error = fmt.Sprintf("agent invocation timed out after %v", timeout)
+			invokeErr = fmt.Errorf("%w after %v", ErrExecutionTimeout, timeout)
+		} else if ctx.Err() == context.Canceled {
+			result.Error = "agent invocation cancelled"
+			result.Cancelled = true
+		} else if errors.Is(err, exec.ErrNotFound) {
+			result.Error = "copilot CLI not found in PATH"
+			invokeErr = fmt.Errorf("%w: %v", ErrCLINotAvailable, err)
 		} else {
 			stderrStr := stderr.String()
 			if stderrStr != "" {
@@ -152,7 +225,7 @@ func (i *Invoker) InvokeAgent(ctx context.Context, agentName, prompt string) (*I
 		)
 	}
 
-	return result, nil
+	return result, invokeErr
 }
 
 // ListAgents lists available agents.
@@ -177,6 +250,7 @@ func (i *Invoker) ListAgents(ctx context.Context) (*InvocationResult, error) {
 		"--agent=orchestrator",
 		"--prompt=List all available agents and their descriptions",
 	)
+	configureProcessCleanup(cmd)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -186,6 +260,10 @@ func (i *Invoker) ListAgents(ctx context.Context) (*InvocationResult, error) {
 	result.Duration = time.Since(start)
 
 	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			result.Error = "copilot CLI not found in PATH"
+			return result, fmt.Errorf("%w: %v", ErrCLINotAvailable, err)
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
 		}
@@ -199,6 +277,37 @@ func (i *Invoker) ListAgents(ctx context.Context) (*InvocationResult, error) {
 	return result, nil
 }
 
+// Timeout returns the per-invocation timeout.
+func (i *Invoker) Timeout() time.Duration {
+	return i.timeout
+}
+
+// Installed reports whether a copilot binary is on PATH. Unlike IsAvailable
+// it does not run the CLI, so it is cheap enough to call per request.
+func (i *Invoker) Installed() bool {
+	_, err := exec.LookPath("copilot")
+	return err == nil
+}
+
+// Version returns the output of copilot --version.
+func (i *Invoker) Version(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "copilot", "--version")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %v", ErrCLINotAvailable, err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("copilot --version failed: %s", msg)
+		}
+		return "", fmt.Errorf("copilot --version failed: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 // IsAvailable checks if the Copilot CLI is available.
 func (i *Invoker) IsAvailable(ctx context.Context) bool {
 	cmd := exec.CommandContext(ctx, "copilot", "--version")
@@ -206,14 +315,16 @@ func (i *Invoker) IsAvailable(ctx context.Context) bool {
 	return err == nil
 }
 
-// CheckAuth checks if Copilot CLI is authenticated.
+// CheckAuth checks if Copilot CLI is authenticated: copilot auth status
+// must exit successfully without reporting that it is not authenticated.
 func (i *Invoker) CheckAuth(ctx context.Context) bool {
 	cmd := exec.CommandContext(ctx, "copilot", "auth", "status")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	err := cmd.Run()
-	if err != nil {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
 		return false
 	}
-	return strings.Contains(stdout.String(), "authenticated") || stdout.String() != ""
+	status := strings.ToLower(output.String())
+	return !strings.Contains(status, "not authenticated") && !strings.Contains(status, "not logged in")
 }
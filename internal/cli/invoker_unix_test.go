@@ -0,0 +1,241 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// installFakeCopilot puts an executable named copilot on PATH that runs script.
+func installFakeCopilot(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "copilot")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake copilot: %v", err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestInvokeAgent_Cancelled(t *testing.T) {
+	// The child sleep keeps stdout open, so Wait only returns promptly if the
+	// whole process group is killed.
+	installFakeCopilot(t, "/bin/sleep 30 & /bin/sleep 30")
+
+	invoker := NewInvoker(time.Minute, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := invoker.InvokeAgent(ctx, "code-reviewer", "review")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > processWaitDelay {
+		t.Errorf("cancellation took %v, expected under %v", elapsed, processWaitDelay)
+	}
+	if !result.Cancelled {
+		t.Error("expected result to be marked cancelled")
+	}
+	if result.Success {
+		t.Error("expected cancelled invocation to be unsuccessful")
+	}
+}
+
+func TestInvokeAgent_Success(t *testing.T) {
+	installFakeCopilot(t, `echo '{"ok":true}'`)
+
+	invoker := NewInvoker(time.Minute, zap.NewNop())
+	result, err := invoker.InvokeAgent(context.Background(), "code-reviewer", "review")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.Cancelled {
+		t.Errorf("expected successful result, got %+v", result)
+	}
+	if string(result.Output) != `{"ok":true}` {
+		t.Errorf("unexpected output: %s", result.Output)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	installFakeCopilot(t, `echo "copilot 0.0.339"`)
+
+	invoker := NewInvoker(time.Minute, zap.NewNop())
+	version, err := invoker.Version(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "copilot 0.0.339" {
+		t.Errorf("unexpected version: %q", version)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := invoker.Version(context.Background()); !errors.Is(err, ErrCLINotAvailable) {
+		t.Errorf("expected ErrCLINotAvailable without copilot, got %v", err)
+	}
+}
+
+func TestInvokeAgent_Options(t *testing.T) {
+	two := 2
+	zero := 0
+
+	tests := []struct {
+		name         string
+		script       string
+		opts         InvocationOptions
+		wantAttempts int
+		wantOutput   string
+		wantArgs     string
+		wantTimeout  bool
+		wantTrunc    bool
+	}{
+		{
+			name:         "default retry",
+			script:       "exit 1",
+			wantAttempts: 2,
+		},
+		{
+			name:         "agent retries",
+			script:       "exit 1",
+			opts:         InvocationOptions{Retries: &two},
+			wantAttempts: 3,
+		},
+		{
+			name:         "no retries",
+			script:       "exit 1",
+			opts:         InvocationOptions{Retries: &zero},
+			wantAttempts: 1,
+		},
+		{
+			name:         "model",
+			script:       `echo '"done"'`,
+			opts:         InvocationOptions{Model: "gpt-5"},
+			wantAttempts: 1,
+			wantOutput:   `"done"`,
+			wantArgs:     "--agent=code-reviewer --prompt=review --model=gpt-5",
+		},
+		{
+			name:         "output limit",
+			script:       `echo 'abcdefghij'`,
+			opts:         InvocationOptions{MaxOutputBytes: 4},
+			wantAttempts: 1,
+			wantOutput:   `"abcd"`,
+			wantTrunc:    true,
+		},
+		{
+			name:         "agent timeout",
+			script:       "/bin/sleep 5",
+			opts:         InvocationOptions{Timeout: 100 * time.Millisecond},
+			wantAttempts: 1,
+			wantTimeout:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := filepath.Join(t.TempDir(), "calls")
+			installFakeCopilot(t, `echo "$@" >> `+log+"\n"+tt.script)
+
+			invoker := NewInvoker(time.Minute, zap.NewNop())
+			ctx := WithInvocationOptions(context.Background(), tt.opts)
+			result, err := invoker.InvokeAgent(ctx, "code-reviewer", "review")
+
+			if tt.wantTimeout != errors.Is(err, ErrExecutionTimeout) {
+				t.Errorf("expected timeout %v, got error %v", tt.wantTimeout, err)
+			}
+
+			data, _ := os.ReadFile(log)
+			calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(calls) != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, len(calls))
+			}
+			if tt.wantArgs != "" && calls[0] != tt.wantArgs {
+				t.Errorf("expected args %q, got %q", tt.wantArgs, calls[0])
+			}
+			if tt.wantOutput != "" && string(result.Output) != tt.wantOutput {
+				t.Errorf("expected output %s, got %s", tt.wantOutput, result.Output)
+			}
+			if result.Truncated != tt.wantTrunc {
+				t.Errorf("expected truncated %v, got %v", tt.wantTrunc, result.Truncated)
+			}
+		})
+	}
+}
+
+func TestInvokeAgent_Instructions(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "args")
+	installFakeCopilot(t, `printf '%s|' "$@" > `+log)
+
+	invoker := NewInvoker(time.Minute, zap.NewNop())
+	ctx := WithInvocationOptions(context.Background(), InvocationOptions{Instructions: "Be brief."})
+	if _, err := invoker.InvokeAgent(ctx, "review-mode", "review auth.go"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Agents the CLI cannot load run without --agent.
+	data, _ := os.ReadFile(log)
+	if want := "--prompt=Be brief.\n\nreview auth.go|"; string(data) != want {
+		t.Errorf("expected args %q, got %q", want, data)
+	}
+}
+
+func TestInvokeAgent_Dir(t *testing.T) {
+	installFakeCopilot(t, "pwd")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoker := NewInvoker(time.Minute, zap.NewNop())
+	ctx := WithInvocationOptions(context.Background(), InvocationOptions{Dir: dir})
+	result, err := invoker.InvokeAgent(ctx, "code-reviewer", "review")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The CLI loads agent files relative to the repository it runs in.
+	var got string
+	if err := json.Unmarshal(result.Output, &got); err != nil || got != dir {
+		t.Errorf("expected the CLI to run in %s, got %s", dir, result.Output)
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{"authenticated", `echo "Logged in to github.com as octocat"`, true},
+		{"no output", "exit 0", true},
+		{"not authenticated", `echo "You are not authenticated. Run copilot auth login."`, false},
+		{"not logged in on stderr", `echo "Not logged in" >&2`, false},
+		{"failed", `echo "authenticated"; exit 1`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installFakeCopilot(t, tt.script)
+
+			invoker := NewInvoker(time.Minute, zap.NewNop())
+			if got := invoker.CheckAuth(context.Background()); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if NewInvoker(time.Minute, zap.NewNop()).CheckAuth(context.Background()) {
+		t.Error("expected no authentication without copilot")
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+// InvocationOptions override an invoker's defaults for the invocations made
+// with a context. The orchestrator sets them from each agent's frontmatter.
+type InvocationOptions struct {
+	// Timeout bounds each attempt. Zero uses the invoker's timeout.
+	Timeout time.Duration
+	// Retries is how often a failed run is retried. Nil uses the invoker's
+	// default.
+	Retries *int
+	// Model is requested from the backend. Empty uses the backend's default.
+	Model string
+	// MaxOutputBytes caps the agent output kept in the result. Zero keeps all
+	// of it.
+	MaxOutputBytes int
+	// Instructions are set for agents the Copilot CLI cannot load by name,
+	// such as chat modes and prompt files. The CLI then runs without
+	// --agent, with the instructions ahead of the prompt. Backends that send
+	// instructions of Agent themselves ignore them.
+	Instructions string
+	// Dir is the working directory of the Copilot CLI, normally the root of
+	// the repository the agent belongs to. Empty uses the server's working
+	// directory.
+	Dir string
+	// Agent is the agent being run, as resolved when its chain was planned.
+	// Backends read its instructions and backend from here rather than from
+	// the live registry, so reloading agents does not change runs already in
+	// flight. Nil makes backends look the agent up by name.
+	Agent *agents.Agent
+}
+
+type optionsKey struct{}
+
+// WithInvocationOptions returns a context whose agent invocations use opts.
+func WithInvocationOptions(ctx context.Context, opts InvocationOptions) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// InvocationOptionsFrom returns the options attached to ctx, or the zero
+// options.
+func InvocationOptionsFrom(ctx context.Context) InvocationOptions {
+	opts, _ := ctx.Value(optionsKey{}).(InvocationOptions)
+	return opts
+}
+
+// limitWriter keeps the first max bytes written to it and discards the
+// rest, so a verbose agent cannot exhaust memory. A max of zero keeps
+// everything.
+type limitWriter struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+// Write stores p up to the limit. It never fails, so the process writing to
+// it is not interrupted.
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.max > 0 {
+		if room := w.max - len(w.buf); len(p) > room {
+			w.buf = append(w.buf, p[:max(room, 0)]...)
+			w.truncated = true
+			return len(p), nil
+		}
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// String returns the bytes kept so far.
+func (w *limitWriter) String() string {
+	return string(w.buf)
+}
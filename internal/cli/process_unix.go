@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessCleanup starts cmd in its own process group and makes
+// context cancellation kill the whole group, so helper processes spawned by
+// the Copilot CLI are torn down together with it.
+func configureProcessCleanup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = processWaitDelay
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package cli
+
+import "os/exec"
+
+// configureProcessCleanup bounds how long Wait blocks on inherited pipes after
+// the process is killed. Windows has no process groups to signal, so context
+// cancellation kills only the Copilot CLI process itself.
+func configureProcessCleanup(cmd *exec.Cmd) {
+	cmd.WaitDelay = processWaitDelay
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,27 @@ type Config struct {
 	// RepoRoot is the path to the repository containing agents.
 	RepoRoot string
 
+	// RepoRoots are the repositories served by one server. The first is the
+	// default repository. Defaults to RepoRoot alone.
+	RepoRoots []string
+
+	// AgentPaths are shared agent directories searched after the repository's
+	// and the user's agents directories, in order of decreasing precedence.
+	AgentPaths []string
+
+	// UserAgentsDir is the user's agents directory, searched after the
+	// repository's. Defaults to copilot-os/agents in the user config
+	// directory, e.g. ~/.config/copilot-os/agents.
+	UserAgentsDir string
+
+	// WatchInterval is how often agent directories are polled for changes
+	// while serving (0 disables hot reload).
+	WatchInterval time.Duration
+
+	// ClientRoots selects how MCP client roots combine with RepoRoots
+	// (merge, replace, off).
+	ClientRoots string
+
 	// LogLevel is the logging level (debug, info, warn, error).
 	LogLevel string
 
@@ -19,6 +42,53 @@ type Config struct {
 
 	// CLITimeout is the timeout for Copilot CLI calls.
 	CLITimeout time.Duration
+
+	// Backend selects how agents are invoked (cli, sampling, auto).
+	Backend string
+
+	// Transport is the MCP transport to serve (stdio, http).
+	Transport string
+
+	// ListenAddr is the address the HTTP transport listens on.
+	ListenAddr string
+
+	// SessionTimeout closes idle HTTP sessions after this duration (0 disables).
+	SessionTimeout time.Duration
+
+	// MaxConcurrentJobs limits how many asynchronous orchestration jobs run at once.
+	MaxConcurrentJobs int
+
+	// JobRetention is how long finished jobs are kept for result retrieval.
+	JobRetention time.Duration
+}
+
+// Setting is a resolved configuration value and the environment variable it
+// is read from.
+type Setting struct {
+	Env   string `json:"env"`
+	Value string `json:"value"`
+}
+
+// Settings returns the resolved configuration as environment variable
+// settings, for diagnostics.
+func (c *Config) Settings() []Setting {
+	return []Setting{
+		{"REPO_ROOT", c.RepoRoot},
+		{"REPO_ROOTS", strings.Join(c.RepoRoots, string(os.PathListSeparator))},
+		{"AGENT_PATHS", strings.Join(c.AgentPaths, string(os.PathListSeparator))},
+		{"AGENT_USER_DIR", c.UserAgentsDir},
+		{"AGENT_WATCH_INTERVAL", c.WatchInterval.String()},
+		{"CLIENT_ROOTS", c.ClientRoots},
+		{"LOG_LEVEL", c.LogLevel},
+		{"CACHE_ENABLED", strconv.FormatBool(c.CacheEnabled)},
+		{"COPILOT_CLI_TIMEOUT", c.CLITimeout.String()},
+		{"AGENT_BACKEND", c.Backend},
+		{"MCP_TRANSPORT", c.Transport},
+		{"MCP_LISTEN", c.ListenAddr},
+		{"MCP_SESSION_TIMEOUT", c.SessionTimeout.String()},
+		{"JOBS_MAX_CONCURRENT", strconv.Itoa(c.MaxConcurrentJobs)},
+		{"JOBS_RETENTION", c.JobRetention.String()},
+	}
 }
 
 // LoadFromEnv loads configuration from environment variables.
@@ -28,10 +98,34 @@ func LoadFromEnv() *Config {
 		LogLevel:     getEnv("LOG_LEVEL", "info"),
 		CacheEnabled: getEnvBool("CACHE_ENABLED", true),
 		CLITimeout:   getEnvDuration("COPILOT_CLI_TIMEOUT", 300*time.Second),
+		Backend:      getEnv("AGENT_BACKEND", "cli"),
+		ClientRoots:  getEnv("CLIENT_ROOTS", "merge"),
+
+		AgentPaths:    getEnvList("AGENT_PATHS", nil),
+		UserAgentsDir: getEnv("AGENT_USER_DIR", defaultUserAgentsDir()),
+		WatchInterval: getEnvDuration("AGENT_WATCH_INTERVAL", 2*time.Second),
+
+		Transport:      getEnv("MCP_TRANSPORT", "stdio"),
+		ListenAddr:     getEnv("MCP_LISTEN", "127.0.0.1:8080"),
+		SessionTimeout: getEnvDuration("MCP_SESSION_TIMEOUT", 30*time.Minute),
+
+		MaxConcurrentJobs: getEnvInt("JOBS_MAX_CONCURRENT", 2),
+		JobRetention:      getEnvDuration("JOBS_RETENTION", time.Hour),
 	}
+	cfg.RepoRoots = getEnvList("REPO_ROOTS", []string{cfg.RepoRoot})
 	return cfg
 }
 
+// defaultUserAgentsDir returns copilot-os/agents in the user config
+// directory, or "" when the user config directory is unknown.
+func defaultUserAgentsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "copilot-os", "agents")
+}
+
 // getEnv retrieves an environment variable or returns a default value.
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
@@ -53,6 +147,35 @@ func getEnvBool(key string, defaultVal bool) bool {
 	return b
 }
 
+// getEnvInt retrieves an integer environment variable or returns a default value.
+func getEnvInt(key string, defaultVal int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return defaultVal
+	}
+	return n
+}
+
+// getEnvList retrieves a list environment variable separated by the OS path
+// list separator (":" on Unix, ";" on Windows) or returns a default value.
+// Empty elements are ignored.
+func getEnvList(key string, defaultVal []string) []string {
+	var list []string
+	for _, item := range filepath.SplitList(os.Getenv(key)) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return defaultVal
+	}
+	return list
+}
+
 // getEnvDuration retrieves a duration environment variable or returns a default value.
 func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 	val := os.Getenv(key)
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -9,9 +11,18 @@ import (
 func TestLoadFromEnv_Defaults(t *testing.T) {
 	// Clear environment variables
 	os.Unsetenv("REPO_ROOT")
+	os.Unsetenv("REPO_ROOTS")
+	os.Unsetenv("CLIENT_ROOTS")
 	os.Unsetenv("LOG_LEVEL")
 	os.Unsetenv("CACHE_ENABLED")
 	os.Unsetenv("COPILOT_CLI_TIMEOUT")
+	os.Unsetenv("AGENT_BACKEND")
+	os.Unsetenv("MCP_TRANSPORT")
+	os.Unsetenv("MCP_LISTEN")
+	os.Unsetenv("MCP_SESSION_TIMEOUT")
+	os.Unsetenv("JOBS_MAX_CONCURRENT")
+	os.Unsetenv("JOBS_RETENTION")
+	os.Unsetenv("AGENT_WATCH_INTERVAL")
 
 	cfg := LoadFromEnv()
 
@@ -19,6 +30,14 @@ func TestLoadFromEnv_Defaults(t *testing.T) {
 		t.Errorf("expected default RepoRoot '.', got %q", cfg.RepoRoot)
 	}
 
+	if len(cfg.RepoRoots) != 1 || cfg.RepoRoots[0] != "." {
+		t.Errorf("expected default RepoRoots [.], got %v", cfg.RepoRoots)
+	}
+
+	if cfg.ClientRoots != "merge" {
+		t.Errorf("expected default ClientRoots 'merge', got %q", cfg.ClientRoots)
+	}
+
 	if cfg.LogLevel != "info" {
 		t.Errorf("expected default LogLevel 'info', got %q", cfg.LogLevel)
 	}
@@ -30,6 +49,34 @@ func TestLoadFromEnv_Defaults(t *testing.T) {
 	if cfg.CLITimeout != 300*time.Second {
 		t.Errorf("expected default CLITimeout 300s, got %v", cfg.CLITimeout)
 	}
+
+	if cfg.Transport != "stdio" {
+		t.Errorf("expected default Transport 'stdio', got %q", cfg.Transport)
+	}
+
+	if cfg.ListenAddr != "127.0.0.1:8080" {
+		t.Errorf("expected default ListenAddr '127.0.0.1:8080', got %q", cfg.ListenAddr)
+	}
+
+	if cfg.SessionTimeout != 30*time.Minute {
+		t.Errorf("expected default SessionTimeout 30m, got %v", cfg.SessionTimeout)
+	}
+
+	if cfg.Backend != "cli" {
+		t.Errorf("expected default Backend 'cli', got %q", cfg.Backend)
+	}
+
+	if cfg.MaxConcurrentJobs != 2 {
+		t.Errorf("expected default MaxConcurrentJobs 2, got %d", cfg.MaxConcurrentJobs)
+	}
+
+	if cfg.JobRetention != time.Hour {
+		t.Errorf("expected default JobRetention 1h, got %v", cfg.JobRetention)
+	}
+
+	if cfg.WatchInterval != 2*time.Second {
+		t.Errorf("expected default WatchInterval 2s, got %v", cfg.WatchInterval)
+	}
 }
 
 func TestLoadFromEnv_CustomValues(t *testing.T) {
@@ -64,6 +111,42 @@ func TestLoadFromEnv_CustomValues(t *testing.T) {
 	}
 }
 
+func TestLoadFromEnv_RepoRoots(t *testing.T) {
+	t.Setenv("REPO_ROOT", "/src/ignored")
+	t.Setenv("REPO_ROOTS", "/src/payments"+string(os.PathListSeparator)+" "+string(os.PathListSeparator)+"/src/search")
+
+	cfg := LoadFromEnv()
+
+	if len(cfg.RepoRoots) != 2 || cfg.RepoRoots[0] != "/src/payments" || cfg.RepoRoots[1] != "/src/search" {
+		t.Errorf("expected RepoRoots [/src/payments /src/search], got %v", cfg.RepoRoots)
+	}
+}
+
+func TestLoadFromEnv_AgentPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/dev/.config")
+	t.Setenv("AGENT_USER_DIR", "")
+	t.Setenv("AGENT_PATHS", "")
+
+	cfg := LoadFromEnv()
+	if len(cfg.AgentPaths) != 0 {
+		t.Errorf("expected no AgentPaths, got %v", cfg.AgentPaths)
+	}
+	if want := filepath.Join("/home/dev/.config", "copilot-os", "agents"); runtime.GOOS == "linux" && cfg.UserAgentsDir != want {
+		t.Errorf("expected default UserAgentsDir %q, got %q", want, cfg.UserAgentsDir)
+	}
+
+	t.Setenv("AGENT_USER_DIR", "/home/dev/agents")
+	t.Setenv("AGENT_PATHS", "/org/agents"+string(os.PathListSeparator)+"/team/agents")
+
+	cfg = LoadFromEnv()
+	if cfg.UserAgentsDir != "/home/dev/agents" {
+		t.Errorf("expected UserAgentsDir /home/dev/agents, got %q", cfg.UserAgentsDir)
+	}
+	if len(cfg.AgentPaths) != 2 || cfg.AgentPaths[0] != "/org/agents" || cfg.AgentPaths[1] != "/team/agents" {
+		t.Errorf("expected AgentPaths [/org/agents /team/agents], got %v", cfg.AgentPaths)
+	}
+}
+
 func TestLoadFromEnv_InvalidTimeout(t *testing.T) {
 	os.Setenv("COPILOT_CLI_TIMEOUT", "invalid")
 	defer os.Unsetenv("COPILOT_CLI_TIMEOUT")
@@ -217,3 +300,51 @@ func TestGetEnvDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvInt(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		defaultValue int
+		envValue     string
+		expected     int
+	}{
+		{
+			name:         "parses valid integer",
+			key:          "INT_VALID",
+			defaultValue: 2,
+			envValue:     "8",
+			expected:     8,
+		},
+		{
+			name:         "uses default on invalid integer",
+			key:          "INT_INVALID",
+			defaultValue: 2,
+			envValue:     "many",
+			expected:     2,
+		},
+		{
+			name:         "uses default when not set",
+			key:          "INT_UNSET",
+			defaultValue: 4,
+			envValue:     "",
+			expected:     4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				os.Setenv(tt.key, tt.envValue)
+				defer os.Unsetenv(tt.key)
+			} else {
+				os.Unsetenv(tt.key)
+			}
+
+			result := getEnvInt(tt.key, tt.defaultValue)
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
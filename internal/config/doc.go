@@ -13,10 +13,21 @@
 //
 // The following environment variables are supported:
 //
-//	REPO_ROOT           - Path to the repository containing agents (default: ".")
-//	LOG_LEVEL           - Logging level: debug, info, warn, error (default: "info")
-//	CACHE_ENABLED       - Enable result caching: true, false (default: true)
-//	COPILOT_CLI_TIMEOUT - Timeout for Copilot CLI calls (default: 300s)
+//	REPO_ROOT            - Path to the repository containing agents (default: ".")
+//	REPO_ROOTS           - Repositories to serve, separated by ":" (";" on Windows) (default: REPO_ROOT)
+//	AGENT_PATHS          - Shared agent directories, separated like REPO_ROOTS (default: none)
+//	AGENT_USER_DIR       - User agent directory (default: copilot-os/agents in the user config dir)
+//	AGENT_WATCH_INTERVAL - Poll agent directories for changes while serving, 0 disables (default: 2s)
+//	CLIENT_ROOTS         - Use MCP client roots: merge, replace, off (default: "merge")
+//	LOG_LEVEL            - Logging level: debug, info, warn, error (default: "info")
+//	CACHE_ENABLED        - Enable result caching: true, false (default: true)
+//	COPILOT_CLI_TIMEOUT  - Timeout for Copilot CLI calls (default: 300s)
+//	AGENT_BACKEND        - Agent backend: cli, sampling, auto (default: "cli")
+//	MCP_TRANSPORT        - MCP transport: stdio, http (default: "stdio")
+//	MCP_LISTEN           - Listen address for the http transport (default: "127.0.0.1:8080")
+//	MCP_SESSION_TIMEOUT  - Idle timeout for http sessions, 0 disables (default: 30m)
+//	JOBS_MAX_CONCURRENT  - Asynchronous orchestration jobs run at once (default: 2)
+//	JOBS_RETENTION       - How long finished jobs are kept (default: 1h)
 //
 // Usage Example
 //
@@ -55,9 +66,17 @@
 //
 // The default values are chosen for development and local testing:
 //   - REPO_ROOT: "." (current directory)
+//   - REPO_ROOTS: REPO_ROOT alone (serve a single repository)
+//   - CLIENT_ROOTS: "merge" (serve client roots alongside REPO_ROOTS)
 //   - LOG_LEVEL: "info" (balanced logging)
 //   - CACHE_ENABLED: true (improve performance)
 //   - COPILOT_CLI_TIMEOUT: 300s (5 minutes, accommodates slow operations)
+//   - AGENT_BACKEND: "cli" (run agents with the Copilot CLI)
+//   - MCP_TRANSPORT: "stdio" (one server process per client)
+//   - MCP_LISTEN: "127.0.0.1:8080" (loopback only)
+//   - MCP_SESSION_TIMEOUT: 30m (reclaim abandoned http sessions)
+//   - JOBS_MAX_CONCURRENT: 2 (avoid overloading the Copilot CLI)
+//   - JOBS_RETENTION: 1h (time for clients to fetch results)
 //
 // For production deployments, consider adjusting:
 //   - LOG_LEVEL: "warn" or "error" (reduce log volume)
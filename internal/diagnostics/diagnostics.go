@@ -0,0 +1,216 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+)
+
+// cliTimeout bounds each Copilot CLI command run by the checks.
+const cliTimeout = 10 * time.Second
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass means the component works.
+	StatusPass Status = "pass"
+	// StatusWarn means the component is degraded or unused.
+	StatusWarn Status = "warn"
+	// StatusFail means agents cannot run until the problem is fixed.
+	StatusFail Status = "fail"
+)
+
+// severity orders statuses from best to worst.
+func (s Status) severity() int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Check is the outcome of a single diagnostic check.
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// BuildInfo identifies the server build.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// CLIInfo describes the Copilot CLI installation.
+type CLIInfo struct {
+	Installed     bool   `json:"installed"`
+	Version       string `json:"version,omitempty"`
+	Authenticated bool   `json:"authenticated"`
+}
+
+// RepoInfo describes the agents discovered in a repository.
+type RepoInfo struct {
+	Name      string                `json:"name"`
+	Root      string                `json:"root"`
+	AgentsDir string                `json:"agents_dir"`
+	Agents    []string              `json:"agents"`
+	Failures  []agents.ParseFailure `json:"failures,omitempty"`
+	Overrides []agents.Override     `json:"overrides,omitempty"`
+}
+
+// Report is the result of a diagnostics run.
+type Report struct {
+	Status      Status           `json:"status"`
+	Checks      []Check          `json:"checks"`
+	Build       BuildInfo        `json:"build"`
+	CLI         CLIInfo          `json:"cli"`
+	Config      []config.Setting `json:"config,omitempty"`
+	Repos       []RepoInfo       `json:"repos"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// CLI is the part of the Copilot CLI invoker the checks use; *cli.Invoker
+// implements it.
+type CLI interface {
+	Installed() bool
+	Version(ctx context.Context) (string, error)
+	CheckAuth(ctx context.Context) bool
+}
+
+// Options configures a diagnostics run.
+type Options struct {
+	// Build is the server build information to report.
+	Build BuildInfo
+
+	// Config is the resolved configuration to report, if known.
+	Config *config.Config
+
+	// CLI checks the Copilot CLI installation.
+	CLI CLI
+
+	// CLIRequired makes Copilot CLI problems fail rather than warn.
+	CLIRequired bool
+
+	// Repos are the repositories whose agents are checked.
+	Repos []*workspace.Repo
+}
+
+// Run performs every check and returns the report.
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{
+		Status:      StatusPass,
+		Build:       opts.Build,
+		Repos:       []RepoInfo{},
+		GeneratedAt: time.Now(),
+	}
+	if opts.Config != nil {
+		report.Config = opts.Config.Settings()
+		report.checkAgentPaths(opts.Config.AgentPaths)
+	}
+
+	report.checkCLI(ctx, opts.CLI, opts.CLIRequired)
+	if len(opts.Repos) == 0 {
+		report.add("repositories", StatusFail, "no repositories configured")
+	}
+	for _, repo := range opts.Repos {
+		report.checkRepo(repo)
+	}
+	return report
+}
+
+// add records a check and updates the report status.
+func (r *Report) add(name string, status Status, message string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: message})
+	if status.severity() > r.Status.severity() {
+		r.Status = status
+	}
+}
+
+// checkCLI checks that the Copilot CLI is installed and authenticated.
+func (r *Report) checkCLI(ctx context.Context, cli CLI, required bool) {
+	problem := StatusWarn
+	if required {
+		problem = StatusFail
+	}
+
+	r.CLI.Installed = cli.Installed()
+	if !r.CLI.Installed {
+		r.add("copilot_cli", problem, "copilot CLI not found in PATH")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cliTimeout)
+	defer cancel()
+
+	version, err := cli.Version(ctx)
+	if err != nil {
+		r.add("copilot_cli", problem, err.Error())
+		return
+	}
+	r.CLI.Version = version
+	r.add("copilot_cli", StatusPass, version)
+
+	r.CLI.Authenticated = cli.CheckAuth(ctx)
+	if r.CLI.Authenticated {
+		r.add("copilot_auth", StatusPass, "authenticated")
+	} else {
+		r.add("copilot_auth", problem, "not authenticated; run copilot auth login")
+	}
+}
+
+// checkAgentPaths checks that the shared agent directories exist.
+func (r *Report) checkAgentPaths(paths []string) {
+	for _, path := range paths {
+		if !isDir(path) {
+			r.add("agent_path:"+path, StatusWarn, fmt.Sprintf("agent directory %s not found", path))
+		}
+	}
+}
+
+// checkRepo checks that a repository has agents and that all of its agent
+// files were parsed.
+func (r *Report) checkRepo(repo *workspace.Repo) {
+	info := RepoInfo{
+		Name:      repo.Name,
+		Root:      repo.Root,
+		AgentsDir: agents.AgentsDir(repo.Root),
+		Agents:    []string{},
+		Failures:  repo.Failures,
+		Overrides: repo.Overrides,
+	}
+	for _, agent := range repo.Registry.All() {
+		info.Agents = append(info.Agents, agent.Name)
+	}
+	r.Repos = append(r.Repos, info)
+
+	name := "repo:" + repo.Name
+	switch {
+	case !isDir(repo.Root):
+		r.add(name, StatusFail, fmt.Sprintf("repository root %s not found", repo.Root))
+	case !isDir(info.AgentsDir) && len(info.Agents) == 0:
+		r.add(name, StatusWarn, fmt.Sprintf("agents directory %s not found", info.AgentsDir))
+	case len(info.Failures) > 0:
+		r.add(name, StatusWarn, fmt.Sprintf("%d agents, %d files failed to parse", len(info.Agents), len(info.Failures)))
+	case len(info.Agents) == 0:
+		r.add(name, StatusWarn, "no agents found")
+	default:
+		r.add(name, StatusPass, fmt.Sprintf("%d agents", len(info.Agents)))
+	}
+}
+
+// isDir reports whether path is an existing directory.
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,149 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// fakeCLI reports a fixed Copilot CLI installation.
+type fakeCLI struct {
+	installed     bool
+	version       string
+	versionErr    error
+	authenticated bool
+}
+
+func (f fakeCLI) Installed() bool                         { return f.installed }
+func (f fakeCLI) Version(context.Context) (string, error) { return f.version, f.versionErr }
+func (f fakeCLI) CheckAuth(context.Context) bool          { return f.authenticated }
+
+// writeAgents creates a repository at root with the given agent files.
+func writeAgents(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, ".github", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkStatus returns the status of the named check, or "" if it is missing.
+func checkStatus(r *Report, name string) Status {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestRun_CLI(t *testing.T) {
+	healthy := fakeCLI{installed: true, version: "copilot 0.0.339", authenticated: true}
+
+	tests := []struct {
+		name       string
+		cli        fakeCLI
+		required   bool
+		wantCLI    Status
+		wantAuth   Status
+		wantReport Status
+	}{
+		{"healthy", healthy, true, StatusPass, StatusPass, StatusPass},
+		{"not installed", fakeCLI{}, true, StatusFail, "", StatusFail},
+		{"not installed, not required", fakeCLI{}, false, StatusWarn, "", StatusWarn},
+		{"version fails", fakeCLI{installed: true, versionErr: errors.New("boom")}, true, StatusFail, "", StatusFail},
+		{"not authenticated", fakeCLI{installed: true, version: "copilot 0.0.339"}, true, StatusPass, StatusFail, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeAgents(t, root, map[string]string{"a.md": "---\nname: a\n---\n"})
+			ws := workspace.New(nil, zap.NewNop())
+			if _, err := ws.Add(root); err != nil {
+				t.Fatal(err)
+			}
+
+			report := Run(context.Background(), Options{CLI: tt.cli, CLIRequired: tt.required, Repos: ws.All()})
+
+			if got := checkStatus(report, "copilot_cli"); got != tt.wantCLI {
+				t.Errorf("copilot_cli: expected %q, got %q", tt.wantCLI, got)
+			}
+			if got := checkStatus(report, "copilot_auth"); got != tt.wantAuth {
+				t.Errorf("copilot_auth: expected %q, got %q", tt.wantAuth, got)
+			}
+			if report.Status != tt.wantReport {
+				t.Errorf("expected report status %q, got %q", tt.wantReport, report.Status)
+			}
+		})
+	}
+}
+
+func TestRun_Repos(t *testing.T) {
+	healthy := fakeCLI{installed: true, version: "copilot 0.0.339", authenticated: true}
+
+	good := filepath.Join(t.TempDir(), "good")
+	writeAgents(t, good, map[string]string{"a.md": "---\nname: a\n---\n"})
+	broken := filepath.Join(t.TempDir(), "broken")
+	writeAgents(t, broken, map[string]string{"a.md": "---\nname: a\n---\n", "bad.md": "no frontmatter"})
+	empty := filepath.Join(t.TempDir(), "empty")
+	writeAgents(t, empty, nil)
+	bare := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	ws := workspace.New(nil, zap.NewNop())
+	for _, root := range []string{good, broken, empty, bare, missing} {
+		if _, err := ws.Add(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := Run(context.Background(), Options{
+		CLI:    healthy,
+		Config: &config.Config{Backend: "cli", AgentPaths: []string{missing}},
+		Repos:  ws.All(),
+		Build:  BuildInfo{Version: "test"},
+	})
+
+	tests := []struct {
+		check string
+		want  Status
+	}{
+		{"repo:good", StatusPass},
+		{"repo:broken", StatusWarn},
+		{"repo:empty", StatusWarn},
+		{"repo:" + filepath.Base(bare), StatusWarn},
+		{"repo:missing", StatusFail},
+		{"agent_path:" + missing, StatusWarn},
+	}
+	for _, tt := range tests {
+		if got := checkStatus(report, tt.check); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.check, tt.want, got)
+		}
+	}
+
+	if report.Status != StatusFail {
+		t.Errorf("expected report status fail, got %q", report.Status)
+	}
+	if len(report.Repos) != 5 || len(report.Repos[1].Failures) != 1 || len(report.Repos[1].Agents) != 1 {
+		t.Errorf("unexpected repository details: %+v", report.Repos)
+	}
+	if len(report.Config) == 0 || report.Build.Version != "test" {
+		t.Error("expected configuration and build information in the report")
+	}
+
+	if none := Run(context.Background(), Options{CLI: healthy}); checkStatus(none, "repositories") != StatusFail {
+		t.Error("expected a failure without repositories")
+	}
+}
@@ -0,0 +1,41 @@
+// Package diagnostics checks that a CopilotOS installation can run agents.
+//
+// A diagnostics run inspects everything agent execution depends on and
+// returns a machine-readable Report. Both the diagnose MCP tool and the
+// copilot-os doctor command are built on it.
+//
+// This package handles:
+//   - Copilot CLI: Whether copilot is on PATH, its version, and its auth state
+//   - Configuration: The resolved configuration settings
+//   - Repositories: Each repository's agents directory, parsed agents, and
+//     the agent files discovery skipped
+//   - Build Information: The version, commit, and build time of the server
+//
+// # Check Status
+//
+// Every check reports one of three statuses:
+//
+//	pass - The component works
+//	warn - The component is degraded or unused but agents can still run
+//	fail - Agents cannot run until the problem is fixed
+//
+// The report's status is the worst status of its checks. Copilot CLI
+// problems fail only when the CLI is required (Options.CLIRequired), which
+// is the case for the cli agent backend; with the sampling backend they are
+// warnings.
+//
+// Usage Example
+//
+//	report := diagnostics.Run(ctx, diagnostics.Options{
+//	    Build:       diagnostics.BuildInfo{Version: "1.0.0"},
+//	    Config:      cfg,
+//	    CLI:         invoker,
+//	    CLIRequired: true,
+//	    Repos:       ws.All(),
+//	})
+//	if report.Status == diagnostics.StatusFail {
+//	    for _, check := range report.Checks {
+//	        fmt.Printf("%s: %s\n", check.Name, check.Message)
+//	    }
+//	}
+package diagnostics
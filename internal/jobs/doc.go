@@ -0,0 +1,62 @@
+// Package jobs runs orchestrations asynchronously for the CopilotOS server.
+//
+// Long agent chains can take minutes, which exceeds the request timeout of
+// many MCP clients. The job manager starts an orchestration in the background
+// and returns a job ID immediately; clients then poll for status and fetch
+// the result once the job has finished.
+//
+// This package handles:
+//   - Job Lifecycle: Queue, run, and record the outcome of orchestrations
+//   - Bounded Concurrency: Limit how many orchestrations run at once
+//   - Progress Tracking: Keep the latest orchestration progress event per job
+//   - Cancellation: Cancel queued or running jobs
+//   - Retention: Forget finished jobs after a retention period
+//
+// # Job Lifecycle
+//
+// Every job moves through these states:
+//
+//	queued ──► running ──► completed
+//	   │          │
+//	   │          ├──────► failed
+//	   │          │
+//	   └──────────┴──────► cancelled
+//
+// A job is queued until one of MaxConcurrent slots is free. Cancelling a
+// queued job removes it from the queue; cancelling a running job cancels the
+// orchestration's context, which kills the running Copilot CLI process.
+//
+// Usage Example
+//
+//	manager := jobs.NewManager(ws, jobs.Options{MaxConcurrent: 2}, logger)
+//	defer manager.Close()
+//
+//	job, err := manager.Start(ctx, "payments", "Review auth.go", nil)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// Later, from another request
+//	status, err := manager.Status(job.ID)
+//	if status.Status.Finished() {
+//	    result, _, err := manager.Result(job.ID)
+//	    ...
+//	}
+//
+// # Repositories
+//
+// Each job records the repository it runs in. NewManager resolves the
+// repository in a workspace.Workspace when the job starts and runs the chain
+// with that repository's orchestrator; an empty repository name selects the
+// workspace's default repository.
+//
+// # Retention
+//
+// Finished jobs are kept for Options.Retention so clients can fetch their
+// results, and at most Options.MaxRetained finished jobs are kept; the oldest
+// are dropped first. Queued and running jobs are never dropped.
+//
+// # Thread Safety
+//
+// The Manager is safe for concurrent use.
+package jobs
@@ -0,0 +1,399 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown or expired job IDs.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobNotFinished is returned when the result of a queued or running
+	// job is requested.
+	ErrJobNotFinished = errors.New("job not finished")
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	// StatusQueued means the job is waiting for a free concurrency slot.
+	StatusQueued Status = "queued"
+	// StatusRunning means the orchestration is executing.
+	StatusRunning Status = "running"
+	// StatusCompleted means the orchestration ran every agent in its chain.
+	StatusCompleted Status = "completed"
+	// StatusFailed means the orchestration returned an error.
+	StatusFailed Status = "failed"
+	// StatusCancelled means the job was cancelled before it finished.
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether s is a terminal state.
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// Job describes an asynchronous orchestration.
+type Job struct {
+	ID         string                      `json:"id"`
+	Status     Status                      `json:"status"`
+	Repo       string                      `json:"repo,omitempty"` // Repository the orchestration runs in
+	Prompt     string                      `json:"prompt"`
+	Agents     []string                    `json:"agents,omitempty"`   // Explicit chain, empty for automatic selection
+	Progress   *orchestrator.ProgressEvent `json:"progress,omitempty"` // Latest progress event
+	Error      string                      `json:"error,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	StartedAt  *time.Time                  `json:"started_at,omitempty"`
+	FinishedAt *time.Time                  `json:"finished_at,omitempty"`
+}
+
+// Options configures a Manager. Zero values select the defaults.
+type Options struct {
+	// MaxConcurrent is the number of jobs that may run at once (default 2).
+	MaxConcurrent int
+
+	// Retention is how long finished jobs are kept (default 1h).
+	Retention time.Duration
+
+	// MaxRetained is the maximum number of finished jobs kept (default 100).
+	MaxRetained int
+}
+
+// runFunc executes a single orchestration in repo.
+type runFunc func(ctx context.Context, repo, prompt string, agents []string) (*orchestrator.ContextState, error)
+
+// entry is the manager's record of a job.
+type entry struct {
+	job    Job
+	result *orchestrator.ContextState
+	err    error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager runs orchestrations as background jobs.
+type Manager struct {
+	run    runFunc
+	opts   Options
+	logger *zap.Logger
+
+	ctx    context.Context // Parent of every job context, cancelled by Close
+	stop   context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	jobs   map[string]*entry
+	now    func() time.Time
+	closed bool
+}
+
+// NewManager creates a job manager that runs orchestrations with the
+// orchestrator of the job's repository in ws. Jobs with an explicit agent
+// list use RunWithExplicitChain, others use RunWithAuto.
+func NewManager(ws *workspace.Workspace, opts Options, logger *zap.Logger) *Manager {
+	return newManager(func(ctx context.Context, repo, prompt string, agents []string) (*orchestrator.ContextState, error) {
+		r, err := ws.Get(repo)
+		if err != nil {
+			return nil, err
+		}
+		orch := r.Orchestrator
+		if len(agents) > 0 {
+			return orch.RunWithExplicitChain(ctx, prompt, agents)
+		}
+		return orch.RunWithAuto(ctx, prompt)
+	}, opts, logger)
+}
+
+// newManager creates a job manager around run.
+func newManager(run runFunc, opts Options, logger *zap.Logger) *Manager {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 2
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = time.Hour
+	}
+	if opts.MaxRetained <= 0 {
+		opts.MaxRetained = 100
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		run:    run,
+		opts:   opts,
+		logger: logger,
+		ctx:    ctx,
+		stop:   stop,
+		slots:  make(chan struct{}, opts.MaxConcurrent),
+		jobs:   make(map[string]*entry),
+		now:    time.Now,
+	}
+}
+
+// Start queues an orchestration of prompt in repo and returns the new job.
+// An empty repo selects the workspace's default repository. agents, when
+// non-empty, is run as an explicit chain.
+//
+// The job keeps the values of ctx, such as an orchestrator.WithInvoker
+// backend, but not its cancellation: the job outlives the request that
+// started it and ends only when it finishes, is cancelled, or the manager
+// is closed.
+func (m *Manager) Start(ctx context.Context, repo, prompt string, agents []string) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return Job{}, errors.New("job manager closed")
+	}
+	m.prune()
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopOnClose := context.AfterFunc(m.ctx, cancel)
+	e := &entry{
+		job: Job{
+			ID:        id,
+			Status:    StatusQueued,
+			Repo:      repo,
+			Prompt:    prompt,
+			Agents:    append([]string(nil), agents...),
+			CreatedAt: m.now(),
+		},
+		cancel: func() {
+			stopOnClose()
+			cancel()
+		},
+		done: make(chan struct{}),
+	}
+	m.jobs[id] = e
+
+	m.wg.Add(1)
+	go m.execute(jobCtx, e)
+
+	m.logger.Info("job queued",
+		zap.String("job_id", id),
+		zap.String("repo", repo),
+		zap.Strings("agents", agents),
+	)
+	return e.snapshot(), nil
+}
+
+// Status returns the current state of a job.
+func (m *Manager) Status(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return e.snapshot(), nil
+}
+
+// Result returns the orchestration state of a finished job along with the
+// job itself. It returns ErrJobNotFinished while the job is queued or
+// running. The state may be partial for cancelled jobs.
+func (m *Manager) Result(id string) (*orchestrator.ContextState, Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return nil, Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if !e.job.Status.Finished() {
+		return nil, e.snapshot(), fmt.Errorf("%w: %s is %s", ErrJobNotFinished, id, e.job.Status)
+	}
+	return e.result, e.snapshot(), nil
+}
+
+// Cancel cancels a queued or running job. Cancelling a finished job has no
+// effect. The returned job may still be running briefly while the
+// orchestration winds down.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if !e.job.Status.Finished() {
+		e.cancel()
+		m.logger.Info("job cancellation requested", zap.String("job_id", id))
+	}
+	return e.snapshot(), nil
+}
+
+// Wait blocks until the job has finished or ctx is done, and returns the
+// job's latest state.
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return m.Status(id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return e.snapshot(), nil
+}
+
+// List returns all retained jobs, newest first.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		jobs = append(jobs, e.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Close cancels every queued and running job and waits for them to finish.
+// Start fails after Close.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	m.stop()
+	m.wg.Wait()
+}
+
+// execute waits for a free slot, runs the job's orchestration, and records
+// the outcome.
+func (m *Manager) execute(ctx context.Context, e *entry) {
+	defer m.wg.Done()
+	defer e.cancel()
+
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		m.finish(e, nil, ctx.Err())
+		return
+	}
+
+	m.mu.Lock()
+	started := m.now()
+	e.job.Status = StatusRunning
+	e.job.StartedAt = &started
+	repo, prompt, agents := e.job.Repo, e.job.Prompt, e.job.Agents
+	m.mu.Unlock()
+
+	m.logger.Info("job started", zap.String("job_id", e.job.ID))
+
+	ctx = orchestrator.WithProgress(ctx, func(ev orchestrator.ProgressEvent) {
+		m.mu.Lock()
+		e.job.Progress = &ev
+		m.mu.Unlock()
+	})
+
+	state, err := m.run(ctx, repo, prompt, agents)
+	m.finish(e, state, err)
+}
+
+// finish records the outcome of a job and wakes any waiters.
+func (m *Manager) finish(e *entry, state *orchestrator.ContextState, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finished := m.now()
+	e.job.FinishedAt = &finished
+	e.result = state
+	e.err = err
+
+	switch {
+	case err == nil:
+		e.job.Status = StatusCompleted
+	case errors.Is(err, context.Canceled):
+		e.job.Status = StatusCancelled
+		e.job.Error = "job cancelled"
+	default:
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+	}
+	close(e.done)
+
+	m.logger.Info("job finished",
+		zap.String("job_id", e.job.ID),
+		zap.String("status", string(e.job.Status)),
+	)
+}
+
+// prune drops finished jobs older than the retention period, then the oldest
+// finished jobs beyond MaxRetained. The caller must hold m.mu.
+func (m *Manager) prune() {
+	cutoff := m.now().Add(-m.opts.Retention)
+
+	var finished []*entry
+	for id, e := range m.jobs {
+		if !e.job.Status.Finished() {
+			continue
+		}
+		if e.job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+			continue
+		}
+		finished = append(finished, e)
+	}
+
+	if excess := len(finished) - m.opts.MaxRetained; excess > 0 {
+		sort.Slice(finished, func(i, j int) bool {
+			return finished[i].job.FinishedAt.Before(*finished[j].job.FinishedAt)
+		})
+		for _, e := range finished[:excess] {
+			delete(m.jobs, e.job.ID)
+		}
+	}
+}
+
+// snapshot returns a copy of the job that is safe to use without holding the
+// manager's lock. The caller must hold m.mu.
+func (e *entry) snapshot() Job {
+	job := e.job
+	job.Agents = append([]string(nil), e.job.Agents...)
+	if e.job.Progress != nil {
+		progress := *e.job.Progress
+		job.Progress = &progress
+	}
+	return job
+}
+
+// newJobID returns a random job identifier.
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return "job-" + hex.EncodeToString(b), nil
+}
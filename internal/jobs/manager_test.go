@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"go.uber.org/zap"
+)
+
+// blockingRun returns a run function that blocks until release is closed or
+// the job is cancelled, and reports each start on started.
+func blockingRun(started chan<- string, release <-chan struct{}) runFunc {
+	return func(ctx context.Context, _, prompt string, _ []string) (*orchestrator.ContextState, error) {
+		started <- prompt
+		select {
+		case <-release:
+			return &orchestrator.ContextState{OriginalPrompt: prompt, Status: orchestrator.StatusCompleted}, nil
+		case <-ctx.Done():
+			return &orchestrator.ContextState{OriginalPrompt: prompt, Status: orchestrator.StatusCancelled}, ctx.Err()
+		}
+	}
+}
+
+func waitJob(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := m.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("Wait(%s) failed: %v", id, err)
+	}
+	if !job.Status.Finished() {
+		t.Fatalf("job %s did not finish, status %s", id, job.Status)
+	}
+	return job
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	m := newManager(blockingRun(started, release), Options{}, zap.NewNop())
+	defer m.Close()
+
+	job, err := m.Start(context.Background(), "", "Review auth.go", nil)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	<-started
+
+	if status, _ := m.Status(job.ID); status.Status != StatusRunning {
+		t.Errorf("expected status running, got %s", status.Status)
+	}
+	if _, _, err := m.Result(job.ID); !errors.Is(err, ErrJobNotFinished) {
+		t.Errorf("expected ErrJobNotFinished, got %v", err)
+	}
+
+	close(release)
+	done := waitJob(t, m, job.ID)
+	if done.Status != StatusCompleted || done.StartedAt == nil || done.FinishedAt == nil {
+		t.Errorf("unexpected finished job: %+v", done)
+	}
+
+	result, _, err := m.Result(job.ID)
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	if result.OriginalPrompt != "Review auth.go" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestManager_BoundedConcurrency(t *testing.T) {
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	m := newManager(blockingRun(started, release), Options{MaxConcurrent: 1}, zap.NewNop())
+	defer m.Close()
+
+	jobs := make(map[string]Job)
+	for _, prompt := range []string{"first", "second"} {
+		jobs[prompt], _ = m.Start(context.Background(), "", prompt, nil)
+	}
+	running := <-started
+	queued := "first"
+	if running == "first" {
+		queued = "second"
+	}
+
+	if status, _ := m.Status(jobs[queued].ID); status.Status != StatusQueued {
+		t.Errorf("expected %s job to be queued, got %s", queued, status.Status)
+	}
+
+	close(release)
+	waitJob(t, m, jobs["first"].ID)
+	waitJob(t, m, jobs["second"].ID)
+}
+
+func TestManager_Cancel(t *testing.T) {
+	started := make(chan string, 1)
+	m := newManager(blockingRun(started, make(chan struct{})), Options{MaxConcurrent: 1}, zap.NewNop())
+	defer m.Close()
+
+	jobs := make(map[string]Job)
+	for _, prompt := range []string{"first", "second"} {
+		jobs[prompt], _ = m.Start(context.Background(), "", prompt, nil)
+	}
+	running, queued := jobs["first"], jobs["second"]
+	if <-started == "second" {
+		running, queued = queued, running
+	}
+
+	for _, id := range []string{queued.ID, running.ID} {
+		if _, err := m.Cancel(id); err != nil {
+			t.Fatalf("Cancel(%s) failed: %v", id, err)
+		}
+		if job := waitJob(t, m, id); job.Status != StatusCancelled {
+			t.Errorf("expected job %s to be cancelled, got %s", id, job.Status)
+		}
+	}
+
+	result, _, err := m.Result(running.ID)
+	if err != nil || result == nil || result.Status != orchestrator.StatusCancelled {
+		t.Errorf("expected partial result for cancelled job, got %+v, %v", result, err)
+	}
+}
+
+func TestManager_Failed(t *testing.T) {
+	m := newManager(func(context.Context, string, string, []string) (*orchestrator.ContextState, error) {
+		return nil, orchestrator.ErrInvalidPrompt
+	}, Options{}, zap.NewNop())
+	defer m.Close()
+
+	job, _ := m.Start(context.Background(), "", "", nil)
+	done := waitJob(t, m, job.ID)
+	if done.Status != StatusFailed || done.Error != orchestrator.ErrInvalidPrompt.Error() {
+		t.Errorf("expected failed job with error, got %+v", done)
+	}
+}
+
+func TestManager_Retention(t *testing.T) {
+	m := newManager(func(_ context.Context, _, prompt string, _ []string) (*orchestrator.ContextState, error) {
+		return &orchestrator.ContextState{OriginalPrompt: prompt}, nil
+	}, Options{Retention: time.Minute, MaxRetained: 2}, zap.NewNop())
+	defer m.Close()
+
+	var ids []string
+	for _, prompt := range []string{"a", "b", "c"} {
+		job, _ := m.Start(context.Background(), "", prompt, nil)
+		waitJob(t, m, job.ID)
+		ids = append(ids, job.ID)
+	}
+
+	if got := len(m.List()); got != 2 {
+		t.Errorf("expected 2 retained jobs, got %d", got)
+	}
+	if _, err := m.Status(ids[0]); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected oldest job to be dropped, got %v", err)
+	}
+
+	m.mu.Lock()
+	m.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	m.mu.Unlock()
+	if got := len(m.List()); got != 0 {
+		t.Errorf("expected expired jobs to be dropped, got %d", got)
+	}
+}
+
+func TestManager_UnknownJob(t *testing.T) {
+	m := newManager(nil, Options{}, zap.NewNop())
+	defer m.Close()
+
+	if _, err := m.Status("job-missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Status: expected ErrJobNotFound, got %v", err)
+	}
+	if _, err := m.Cancel("job-missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Cancel: expected ErrJobNotFound, got %v", err)
+	}
+	if _, _, err := m.Result("job-missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Result: expected ErrJobNotFound, got %v", err)
+	}
+}
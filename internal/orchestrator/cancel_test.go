@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+func TestRunWithExplicitChain_Cancelled(t *testing.T) {
+	orch := newTestOrchestrator(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	state, err := orch.RunWithExplicitChain(ctx, "Review auth.go and add tests", []string{"code-reviewer", "test-generator"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if state.Status != StatusCancelled {
+		t.Errorf("expected status %q, got %q", StatusCancelled, state.Status)
+	}
+	if len(state.CompletedAgents) != 0 {
+		t.Errorf("expected no completed agents, got %v", state.CompletedAgents)
+	}
+	if want := []string{"code-reviewer", "test-generator"}; !reflect.DeepEqual(state.AbortedAgents, want) {
+		t.Errorf("expected aborted agents %v, got %v", want, state.AbortedAgents)
+	}
+}
+
+func TestRunWithExplicitChain_Completed(t *testing.T) {
+	orch := newTestOrchestrator(t)
+
+	state, err := orch.RunWithExplicitChain(context.Background(), "Review auth.go", []string{"code-reviewer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Status != StatusCompleted {
+		t.Errorf("expected status %q, got %q", StatusCompleted, state.Status)
+	}
+	if want := []string{"code-reviewer"}; !reflect.DeepEqual(state.CompletedAgents, want) {
+		t.Errorf("expected completed agents %v, got %v", want, state.CompletedAgents)
+	}
+	if len(state.AbortedAgents) != 0 {
+		t.Errorf("expected no aborted agents, got %v", state.AbortedAgents)
+	}
+}
+
+// interruptingInvoker runs every agent successfully except interrupt, which
+// calls stop, waits for the run's context to be done and then fails like a
+// backend that does not report cancellation.
+type interruptingInvoker struct {
+	interrupt string
+	stop      func()
+}
+
+func (i interruptingInvoker) InvokeAgent(ctx context.Context, agentName, _ string) (*cli.InvocationResult, error) {
+	if agentName != i.interrupt {
+		return &cli.InvocationResult{Agent: agentName, Success: true}, nil
+	}
+	i.stop()
+	<-ctx.Done()
+	return &cli.InvocationResult{Agent: agentName, Error: "connection closed", ExitCode: 1}, errors.New("connection closed")
+}
+
+func TestRunWithExplicitChain_Interrupted(t *testing.T) {
+	registry := agents.NewRegistry()
+	for _, name := range []string{"code-reviewer", "test-generator", "doc-writer"} {
+		registry.Add(&agents.Agent{Name: name})
+	}
+
+	tests := []struct {
+		name       string
+		ctx        func() (context.Context, context.CancelFunc)
+		cancelRun  bool
+		wantErr    error
+		wantStatus RunStatus
+	}{
+		{
+			name:       "cancelled",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			cancelRun:  true,
+			wantErr:    context.Canceled,
+			wantStatus: StatusCancelled,
+		},
+		{
+			name: "timed out",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr:    context.DeadlineExceeded,
+			wantStatus: StatusTimedOut,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			stop := func() {}
+			if tt.cancelRun {
+				stop = cancel
+			}
+			orch := NewOrchestrator(registry, interruptingInvoker{interrupt: "test-generator", stop: stop}, zap.NewNop())
+
+			state, err := orch.RunWithExplicitChain(ctx, "Review, test and document auth.go", []string{"code-reviewer", "test-generator", "doc-writer"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if state.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, state.Status)
+			}
+			if want := []string{"code-reviewer"}; !reflect.DeepEqual(state.CompletedAgents, want) {
+				t.Errorf("expected completed agents %v, got %v", want, state.CompletedAgents)
+			}
+			if want := []string{"test-generator", "doc-writer"}; !reflect.DeepEqual(state.AbortedAgents, want) {
+				t.Errorf("expected aborted agents %v, got %v", want, state.AbortedAgents)
+			}
+		})
+	}
+}
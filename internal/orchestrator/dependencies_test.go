@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+func TestAgentDependencies(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "coding-engineer", Keywords: []string{"implement"}})
+	registry.Add(&agents.Agent{
+		Name:     "test-generator",
+		Keywords: []string{"testing"},
+		Requires: []string{"coding-engineer"},
+	})
+	registry.Add(&agents.Agent{
+		Name:     "code-reviewer",
+		Keywords: []string{"quality"},
+		After:    []string{"test-generator"},
+	})
+	registry.Add(&agents.Agent{Name: "loop-a", Keywords: []string{"loop"}, After: []string{"loop-b"}})
+	registry.Add(&agents.Agent{Name: "loop-b", Keywords: []string{"loop"}, After: []string{"loop-a"}})
+
+	invoker := &optionsInvoker{}
+	orch := NewOrchestrator(registry, invoker, zap.NewNop())
+
+	t.Run("plan pulls in and orders required agents", func(t *testing.T) {
+		state, err := orch.Plan(context.Background(), "Improve code quality and testing of auth.go")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"coding-engineer", "test-generator", "code-reviewer"}
+		if !reflect.DeepEqual(state.SelectedAgents, want) {
+			t.Errorf("expected %v, got %v", want, state.SelectedAgents)
+		}
+		if !strings.Contains(state.SelectionRationale, "Required: coding-engineer") {
+			t.Errorf("expected rationale to name required agents, got %q", state.SelectionRationale)
+		}
+	})
+
+	t.Run("explicit chain is resolved", func(t *testing.T) {
+		state, err := orch.RunWithExplicitChain(context.Background(), "Review auth.go", []string{"code-reviewer", "test-generator"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"coding-engineer", "test-generator", "code-reviewer"}
+		if !reflect.DeepEqual(state.SelectedAgents, want) {
+			t.Errorf("expected %v, got %v", want, state.SelectedAgents)
+		}
+		if !reflect.DeepEqual(state.CompletedAgents, want) {
+			t.Errorf("expected agents to run in order %v, got %v", want, state.CompletedAgents)
+		}
+	})
+
+	t.Run("cycle fails the run", func(t *testing.T) {
+		state, err := orch.RunWithExplicitChain(context.Background(), "Loop", []string{"loop-a", "loop-b"})
+		if !errors.Is(err, agents.ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+		if state.Status != StatusFailed {
+			t.Errorf("expected status %q, got %q", StatusFailed, state.Status)
+		}
+	})
+}
@@ -7,6 +7,8 @@
 //   - Context Management: Pass accumulated context between agents
 //   - Result Synthesis: Combine outputs from multiple agents
 //   - Error Recovery: Handle agent failures gracefully
+//   - Progress Reporting: Report each step of long-running chains
+//   - Cancellation: Stop chains promptly and return partial results
 //
 // # Orchestration Modes
 //
@@ -22,7 +24,7 @@
 //
 // 2. Explicit Mode (RunWithExplicitChain):
 //   - Uses specified agent names
-//   - Executes in given order
+//   - Executes in given order, adjusted only for declared dependencies
 //   - No automatic selection
 //   - Full control over chain
 //
@@ -49,6 +51,7 @@
 //     - Match keywords to agent capabilities
 //     - Rank agents by relevance score
 //     - Select top N agents (default: 2)
+//     - Add required agents and order the chain by dependencies
 //
 //  5. Chain Execution:
 //     - Invoke first agent with refined prompt
@@ -113,6 +116,13 @@
 //  3. Rank agents by score (descending)
 //  4. Select top N agents
 //  5. If no matches, fall back to top general-purpose agents
+//  6. Resolve dependencies with agents.Registry.Resolve
+//
+// Agents declare dependencies in their frontmatter. requires pulls the named
+// agents into the chain and runs them first; after and before only order
+// agents that are already in the chain. Agents without constraints between
+// them keep their selection order. Declarations that form a cycle fail the
+// run with an error matching agents.ErrDependencyCycle.
 //
 // Scoring Formula:
 //   - Direct keyword match: +1.0 point
@@ -155,6 +165,10 @@
 //	fmt.Printf("Selected agents: %v\n", state.SelectedAgents)
 //	fmt.Printf("Final output: %s\n", state.FinalOutput)
 //
+// Plan runs the evaluation and selection steps of automatic mode without
+// executing any agent, returning a ContextState with status "planned". It is
+// used to preview which agents a prompt would run.
+//
 // Usage Example (Explicit Mode)
 //
 //	// Specify exact agent chain
@@ -166,6 +180,18 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Progress Reporting
+//
+// Attach a ProgressFunc to the context with WithProgress to observe a run.
+// Events are emitted at evaluation, selection, each agent start and finish
+// (with agent name, success, and duration), and synthesis. Each event carries
+// a 1-based Step and, once the chain is known, the expected Total:
+//
+//	ctx = orchestrator.WithProgress(ctx, func(ev orchestrator.ProgressEvent) {
+//	    fmt.Printf("[%d/%d] %s\n", ev.Step, ev.Total, ev.Message)
+//	})
+//	state, err := orch.RunWithAuto(ctx, "Review authentication code")
+//
 // # Context State Structure
 //
 // The ContextState captures the entire execution:
@@ -177,6 +203,41 @@
 //   - SelectedAgents: Names of agents executed
 //   - SelectionRationale: Why these agents were chosen
 //   - TotalDuration: Total execution time in milliseconds
+//   - Status: completed, cancelled, failed, or planned
+//   - CompletedAgents: Agents that ran to completion
+//   - AbortedAgents: Agents interrupted or skipped by cancellation
+//   - RegistryVersion: Version of the agent registry snapshot the run used
+//
+// Each run plans and executes against a single registry snapshot, so agents
+// reloaded or replaced during a run do not affect it.
+//
+// # Invocation Backends
+//
+// Agents are run through the Invoker passed to NewOrchestrator, normally a
+// *cli.Invoker. A run can use a different backend, such as MCP sampling,
+// by attaching it to the context:
+//
+//	ctx = orchestrator.WithInvoker(ctx, samplingInvoker)
+//	state, err := orch.RunWithAuto(ctx, prompt)
+//
+// The Copilot CLI loads an agent's file itself and the sampling invoker sends
+// the instructions as the system prompt; both report this by implementing
+// InstructionLoader. For any other invoker, each agent prompt also carries
+// the agent's Markdown instructions (agents.Agent.Instructions).
+//
+// # Cancellation
+//
+// Cancelling the run's context stops the chain: the agent in flight has its
+// Copilot CLI process killed, later agents are never started, and the run
+// returns the partial ContextState (Status "cancelled", or "timed_out" when
+// the context's deadline passed) along with ctx.Err(). The agent in flight is
+// listed in AbortedAgents unless it succeeded, even if its invoker reported
+// an ordinary failure:
+//
+//	state, err := orch.RunWithAuto(ctx, prompt)
+//	if errors.Is(err, context.Canceled) {
+//	    fmt.Println("completed:", state.CompletedAgents, "aborted:", state.AbortedAgents)
+//	}
 //
 // # Performance Considerations
 //
@@ -0,0 +1,12 @@
+package orchestrator
+
+import "errors"
+
+var (
+	// ErrInvalidPrompt is returned when a run is started with an empty prompt.
+	ErrInvalidPrompt = errors.New("prompt cannot be empty")
+
+	// ErrOrchestrationFailed is wrapped by errors that stop a run before any
+	// agent can execute, such as an empty registry.
+	ErrOrchestrationFailed = errors.New("orchestration failed")
+)
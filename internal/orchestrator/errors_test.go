@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		chain  []string
+		want   error
+	}{
+		{"auto empty prompt", "   ", nil, ErrInvalidPrompt},
+		{"explicit empty prompt", "", []string{"code-reviewer"}, ErrInvalidPrompt},
+		{"explicit unknown agent", "Review auth.go", []string{"nonexistent"}, agents.ErrAgentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orch := newTestOrchestrator(t)
+
+			var (
+				state *ContextState
+				err   error
+			)
+			if tt.chain != nil {
+				state, err = orch.RunWithExplicitChain(context.Background(), tt.prompt, tt.chain)
+			} else {
+				state, err = orch.RunWithAuto(context.Background(), tt.prompt)
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if state.Status != StatusFailed {
+				t.Errorf("expected status %q, got %q", StatusFailed, state.Status)
+			}
+		})
+	}
+}
+
+func TestRunWithAuto_NoAgents(t *testing.T) {
+	orch := NewOrchestrator(agents.NewRegistry(), nil, zap.NewNop())
+
+	_, err := orch.RunWithAuto(context.Background(), "Review auth.go for security issues")
+	if !errors.Is(err, ErrOrchestrationFailed) {
+		t.Errorf("expected ErrOrchestrationFailed, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+// recordingInvoker records the prompts it is asked to run.
+type recordingInvoker struct {
+	prompts []string
+}
+
+func (r *recordingInvoker) InvokeAgent(_ context.Context, agentName, prompt string) (*cli.InvocationResult, error) {
+	r.prompts = append(r.prompts, prompt)
+	return &cli.InvocationResult{Agent: agentName, Success: true}, nil
+}
+
+// loaderInvoker is a recordingInvoker implementing InstructionLoader.
+type loaderInvoker struct {
+	*recordingInvoker
+	loads bool
+}
+
+func (l loaderInvoker) LoadsInstructions() bool { return l.loads }
+
+func TestRunWithExplicitChain_Instructions(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Description: "Reviews code", Instructions: "Check error handling."})
+
+	tests := []struct {
+		name string
+		wrap func(*recordingInvoker) Invoker
+		want bool
+	}{
+		{"plain invoker", func(r *recordingInvoker) Invoker { return r }, true},
+		{"loads instructions", func(r *recordingInvoker) Invoker { return loaderInvoker{r, true} }, false},
+		{"does not load instructions", func(r *recordingInvoker) Invoker { return loaderInvoker{r, false} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingInvoker{}
+			orch := NewOrchestrator(registry, tt.wrap(recorder), zap.NewNop())
+
+			if _, err := orch.RunWithExplicitChain(context.Background(), "Review auth.go", []string{"code-reviewer"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			prompts := recorder.prompts
+			if len(prompts) != 1 {
+				t.Fatalf("expected 1 prompt, got %d", len(prompts))
+			}
+			if got := strings.Contains(prompts[0], "Check error handling."); got != tt.want {
+				t.Errorf("expected instructions included = %v, got prompt:\n%s", tt.want, prompts[0])
+			}
+		})
+	}
+}
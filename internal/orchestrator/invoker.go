@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/rayprogramming/copilot-os/internal/cli"
+)
+
+// Invoker runs a single agent with a prepared prompt. *cli.Invoker runs
+// agents through the Copilot CLI; *sampling.Invoker asks the connected MCP
+// client to generate the response instead.
+type Invoker interface {
+	InvokeAgent(ctx context.Context, agentName, prompt string) (*cli.InvocationResult, error)
+}
+
+// InstructionLoader is implemented by invokers whose backend supplies each
+// agent's instructions itself: the Copilot CLI loads the agent file named by
+// --agent, and the sampling invoker sends the instructions as the system
+// prompt. For invokers that do not implement it, or report false, the
+// orchestrator includes the agent's instructions in the prompt.
+type InstructionLoader interface {
+	LoadsInstructions() bool
+}
+
+// includeInstructions reports whether agent prompts for inv must carry the
+// agent's instructions.
+func includeInstructions(inv Invoker) bool {
+	loader, ok := inv.(InstructionLoader)
+	return !ok || !loader.LoadsInstructions()
+}
+
+type invokerKey struct{}
+
+// WithInvoker returns a context whose orchestration runs invoke agents with
+// inv instead of the Orchestrator's default invoker. This lets a caller pick
+// the invocation backend per request, e.g. MCP sampling through the client
+// that sent the request.
+func WithInvoker(ctx context.Context, inv Invoker) context.Context {
+	return context.WithValue(ctx, invokerKey{}, inv)
+}
+
+// invokerFor returns the invoker attached to ctx, or the Orchestrator's
+// default invoker.
+func (o *Orchestrator) invokerFor(ctx context.Context) Invoker {
+	if inv, ok := ctx.Value(invokerKey{}).(Invoker); ok {
+		return inv
+	}
+	return o.invoker
+}
@@ -2,8 +2,10 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/rayprogramming/copilot-os/internal/agents"
 	"github.com/rayprogramming/copilot-os/internal/cli"
@@ -21,18 +23,42 @@ type ContextState struct {
 	SelectedAgents     []string                `json:"selected_agents"`
 	SelectionRationale string                  `json:"selection_rationale"`
 	TotalDuration      int64                   `json:"total_duration_ms"`
+	Status             RunStatus               `json:"status"`
+	CompletedAgents    []string                `json:"completed_agents"`
+	AbortedAgents      []string                `json:"aborted_agents,omitempty"` // Agents interrupted or never started because the run was cancelled or timed out
+	RegistryVersion    uint64                  `json:"registry_version"`         // Version of the agent registry snapshot the run used
 }
 
+// RunStatus describes how an orchestration run ended.
+type RunStatus string
+
+const (
+	// StatusCompleted means every agent in the chain ran.
+	StatusCompleted RunStatus = "completed"
+	// StatusCancelled means the run's context was cancelled before the chain
+	// finished. The state holds the results gathered so far.
+	StatusCancelled RunStatus = "cancelled"
+	// StatusTimedOut means the run's context deadline passed before the chain
+	// finished. The state holds the results gathered so far.
+	StatusTimedOut RunStatus = "timed_out"
+	// StatusFailed means the run stopped before any agent could execute.
+	StatusFailed RunStatus = "failed"
+	// StatusPlanned means agents were selected by Plan but not executed.
+	StatusPlanned RunStatus = "planned"
+)
+
 // Orchestrator orchestrates agent chains intelligently.
 type Orchestrator struct {
 	registry  *agents.Registry
-	invoker   *cli.Invoker
+	invoker   Invoker
 	evaluator *prompt.Evaluator
 	logger    *zap.Logger
+	dir       string // Working directory of agent invocations
 }
 
-// NewOrchestrator creates a new orchestrator.
-func NewOrchestrator(registry *agents.Registry, invoker *cli.Invoker, logger *zap.Logger) *Orchestrator {
+// NewOrchestrator creates a new orchestrator. Agents are run with invoker
+// unless a run's context carries another one (see WithInvoker).
+func NewOrchestrator(registry *agents.Registry, invoker Invoker, logger *zap.Logger) *Orchestrator {
 	return &Orchestrator{
 		registry:  registry,
 		invoker:   invoker,
@@ -41,12 +67,75 @@ func NewOrchestrator(registry *agents.Registry, invoker *cli.Invoker, logger *za
 	}
 }
 
+// SetDir sets the directory agents run in, normally the root of the
+// repository the registry was discovered from. It is passed to invokers as
+// cli.InvocationOptions.Dir and must be set before the orchestrator is used.
+func (o *Orchestrator) SetDir(dir string) {
+	o.dir = dir
+}
+
 // RunWithAuto automatically evaluates the prompt, selects agents, and executes the chain.
+//
+// It returns ErrInvalidPrompt for an empty prompt and an error wrapping
+// ErrOrchestrationFailed when no agents are available.
 func (o *Orchestrator) RunWithAuto(ctx context.Context, userPrompt string) (*ContextState, error) {
 	state := &ContextState{
 		OriginalPrompt: userPrompt,
 		AgentResults:   []cli.InvocationResult{},
 	}
+	if strings.TrimSpace(userPrompt) == "" {
+		state.Status = StatusFailed
+		return state, ErrInvalidPrompt
+	}
+	progress := newProgressTracker(ctx)
+	agentSet := o.snapshot(state)
+
+	selectedAgents, err := o.planChain(state, agentSet, progress)
+	if err != nil {
+		state.Status = StatusFailed
+		return state, err
+	}
+
+	// Step 4: Execute agent chain
+	finalOutput, results, err := o.executeChain(ctx, state.RefinedPrompt, selectedAgents, ContextState{}, progress)
+	o.recordChain(state, selectedAgents, finalOutput, results, err)
+	if err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+// Plan evaluates the prompt and selects agents exactly as RunWithAuto does,
+// without executing them. The returned state has status StatusPlanned and no
+// agent results, so callers can preview a run.
+//
+// It returns the same errors as RunWithAuto for an empty prompt or an empty
+// registry.
+func (o *Orchestrator) Plan(ctx context.Context, userPrompt string) (*ContextState, error) {
+	state := &ContextState{
+		OriginalPrompt: userPrompt,
+		AgentResults:   []cli.InvocationResult{},
+	}
+	if strings.TrimSpace(userPrompt) == "" {
+		state.Status = StatusFailed
+		return state, ErrInvalidPrompt
+	}
+
+	if _, err := o.planChain(state, o.snapshot(state), newProgressTracker(ctx)); err != nil {
+		state.Status = StatusFailed
+		return state, err
+	}
+	state.Status = StatusPlanned
+	return state, nil
+}
+
+// planChain evaluates and refines the prompt in state, selects the agents to
+// run from agentSet, and records the evaluation and selection on state. Both
+// are reported to progress once the chain is known, so every event carries
+// the run's total.
+func (o *Orchestrator) planChain(state *ContextState, agentSet *agents.Snapshot, progress *progressTracker) ([]*agents.Agent, error) {
+	userPrompt := state.OriginalPrompt
 
 	// Step 1: Evaluate prompt
 	o.logger.Debug("evaluating prompt", zap.String("prompt", userPrompt))
@@ -64,36 +153,51 @@ func (o *Orchestrator) RunWithAuto(ctx context.Context, userPrompt string) (*Con
 
 	// Step 2: Extract keywords and select agents
 	keywords := o.extractKeywords(refinedPrompt)
-	selectedAgents := o.selectAgents(keywords, 2) // Select up to 2 agents by default
+	selectedAgents := o.selectAgents(agentSet, keywords, 2) // Select up to 2 agents by default
 
 	if len(selectedAgents) == 0 {
 		o.logger.Warn("no agents selected, trying broader search")
 		// If no agents matched, select top agents
-		selectedAgents = o.selectTopAgents(3)
+		selectedAgents = o.selectTopAgents(agentSet, 3)
+	}
+	if len(selectedAgents) == 0 {
+		return nil, fmt.Errorf("%w: no agents available", ErrOrchestrationFailed)
 	}
 
-	state.SelectedAgents = o.agentNames(selectedAgents)
-	state.SelectionRationale = o.buildRationale(keywords, selectedAgents)
+	// Step 3: Add required agents and order the chain by dependencies
+	chain, err := agentSet.Resolve(selectedAgents)
+	if err != nil {
+		return nil, err
+	}
+
+	state.SelectedAgents = o.agentNames(chain)
+	state.SelectionRationale = o.buildRationale(keywords, selectedAgents, chain)
 
 	o.logger.Info("agents selected",
 		zap.Strings("agents", state.SelectedAgents),
 		zap.String("rationale", state.SelectionRationale),
 	)
-
-	// Step 3: Execute agent chain
-	finalOutput, results, err := o.executeChain(ctx, refinedPrompt, selectedAgents, ContextState{})
-	if err != nil {
-		o.logger.Error("chain execution failed", zap.Error(err))
-		return state, err
-	}
-
-	state.AgentResults = results
-	state.FinalOutput = finalOutput
-
-	return state, nil
+	progress.setAgentCount(len(chain))
+	progress.report(ProgressEvent{
+		Stage:   StageEvaluation,
+		Message: fmt.Sprintf("Prompt evaluated (confidence %.2f)", evaluation.Confidence),
+	})
+	progress.report(ProgressEvent{
+		Stage:   StageSelection,
+		Message: "Selected agents: " + strings.Join(state.SelectedAgents, ", "),
+	})
+
+	return chain, nil
 }
 
-// RunWithExplicitChain executes agents in a specific order.
+// RunWithExplicitChain executes agents in a specific order. Agents they
+// require are added, and the chain is reordered only where the agents'
+// requires, after and before declarations demand it (see
+// agents.Registry.Resolve).
+//
+// It returns ErrInvalidPrompt for an empty prompt, an error matching
+// agents.ErrAgentNotFound when a named agent is not registered, and an error
+// matching agents.ErrDependencyCycle when the declarations form a cycle.
 func (o *Orchestrator) RunWithExplicitChain(ctx context.Context, userPrompt string, agentNames []string) (*ContextState, error) {
 	state := &ContextState{
 		OriginalPrompt: userPrompt,
@@ -101,70 +205,118 @@ func (o *Orchestrator) RunWithExplicitChain(ctx context.Context, userPrompt stri
 		SelectedAgents: agentNames,
 		AgentResults:   []cli.InvocationResult{},
 	}
+	if strings.TrimSpace(userPrompt) == "" {
+		state.Status = StatusFailed
+		return state, ErrInvalidPrompt
+	}
+	progress := newProgressTracker(ctx)
+	agentSet := o.snapshot(state)
 
 	// Get agent objects
 	selectedAgents := make([]*agents.Agent, 0)
 	for _, name := range agentNames {
-		agent := o.registry.Get(name)
-		if agent == nil {
-			return state, fmt.Errorf("agent %q not found", name)
+		agent, err := o.lookupAgent(agentSet, name)
+		if err != nil {
+			state.Status = StatusFailed
+			return state, err
 		}
 		selectedAgents = append(selectedAgents, agent)
 	}
+	selectedAgents, err := agentSet.Resolve(selectedAgents)
+	if err != nil {
+		state.Status = StatusFailed
+		return state, err
+	}
+	state.SelectedAgents = o.agentNames(selectedAgents)
 
 	// Evaluate prompt (but don't change it)
 	evaluation := o.evaluator.Evaluate(userPrompt)
 	state.EvaluationFeedback = evaluation
+	progress.setAgentCount(len(selectedAgents))
+	progress.report(ProgressEvent{
+		Stage:   StageEvaluation,
+		Message: fmt.Sprintf("Prompt evaluated (confidence %.2f)", evaluation.Confidence),
+	})
+	progress.report(ProgressEvent{
+		Stage:   StageSelection,
+		Message: "Explicit chain: " + strings.Join(state.SelectedAgents, ", "),
+	})
 
 	// Execute chain
-	finalOutput, results, err := o.executeChain(ctx, userPrompt, selectedAgents, ContextState{})
+	finalOutput, results, err := o.executeChain(ctx, userPrompt, selectedAgents, ContextState{}, progress)
+	o.recordChain(state, selectedAgents, finalOutput, results, err)
 	if err != nil {
 		return state, err
 	}
 
-	state.AgentResults = results
-	state.FinalOutput = finalOutput
-
 	return state, nil
 }
 
-// executeChain executes a sequence of agents with context flow.
-func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents []*agents.Agent, initialContext ContextState) (string, []cli.InvocationResult, error) {
+// executeChain executes a sequence of agents with context flow, reporting
+// each agent's start and finish and the final synthesis to progress.
+//
+// If ctx is cancelled or its deadline passes, the running agent's CLI process
+// is killed and marked Cancelled unless it succeeded, no further agents are
+// started, and the output of the agents that ran is synthesized and returned
+// together with ctx.Err().
+func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents []*agents.Agent, initialContext ContextState, progress *progressTracker) (string, []cli.InvocationResult, error) {
 	results := []cli.InvocationResult{}
 	contextState := initialContext
+	invoker := o.invokerFor(ctx)
+	withInstructions := includeInstructions(invoker)
 
 	for _, agent := range agents {
-		select {
-		case <-ctx.Done():
-			return "", results, ctx.Err()
-		default:
+		if ctx.Err() != nil {
+			break
 		}
 
 		// Build agent prompt with context
-		agentPrompt := o.buildAgentPrompt(prompt, agent, contextState)
+		agentPrompt := o.buildAgentPrompt(prompt, agent, contextState, withInstructions)
 
 		o.logger.Debug("invoking agent",
 			zap.String("agent", agent.Name),
 			zap.String("prompt", agentPrompt),
 		)
+		progress.report(ProgressEvent{
+			Stage:   StageAgentStart,
+			Agent:   agent.Name,
+			Message: fmt.Sprintf("Running %s", agent.Name),
+		})
 
 		// Invoke agent
-		result, err := o.invoker.InvokeAgent(ctx, agent.Name, agentPrompt)
+		result, err := o.invoke(ctx, invoker, agent, agentPrompt)
 		if err != nil {
 			o.logger.Error("agent invocation error",
 				zap.String("agent", agent.Name),
 				zap.Error(err),
 			)
 			// Continue to next agent instead of failing
-			result = &cli.InvocationResult{
-				Agent:    agent.Name,
-				Success:  false,
-				Error:    err.Error(),
-				ExitCode: 1,
+			if result == nil {
+				result = &cli.InvocationResult{
+					Agent:    agent.Name,
+					Success:  false,
+					Error:    err.Error(),
+					ExitCode: 1,
+				}
 			}
 		}
 
+		// An agent that did not succeed before the run was cancelled or timed
+		// out was interrupted, whatever its invoker reported.
+		if !result.Success && ctx.Err() != nil {
+			result.Cancelled = true
+		}
 		results = append(results, *result)
+		if result.Cancelled {
+			break
+		}
+		progress.report(ProgressEvent{
+			Stage:    StageAgentFinish,
+			Agent:    agent.Name,
+			Success:  result.Success,
+			Duration: result.Duration.Milliseconds(),
+			Message:  agentFinishMessage(result),
+		})
 
 		// If agent succeeded, include output in context
 		if result.Success && result.Output != nil {
@@ -174,10 +326,114 @@ func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents [
 
 	// Synthesize final output
 	finalOutput := o.synthesizeOutput(contextState, results)
+	if err := ctx.Err(); err != nil {
+		return finalOutput, results, err
+	}
+	progress.report(ProgressEvent{
+		Stage:   StageSynthesis,
+		Message: fmt.Sprintf("Synthesized results from %d agents", len(results)),
+	})
 
 	return finalOutput, results, nil
 }
 
+// snapshot returns the registry snapshot a run uses for all of its agent
+// lookups, so agents reloaded during the run do not affect it, and records
+// its version on state.
+func (o *Orchestrator) snapshot(state *ContextState) *agents.Snapshot {
+	agentSet := o.registry.Snapshot()
+	state.RegistryVersion = agentSet.Version()
+	return agentSet
+}
+
+// InvokeAgent runs a single registered agent with prompt, applying the
+// execution settings of its frontmatter, with the invoker attached to ctx or
+// the Orchestrator's default one. The prompt is sent as is.
+//
+// It returns an error matching agents.ErrAgentNotFound for an unknown agent
+// and agents.ErrAgentDisabled for a disabled one.
+func (o *Orchestrator) InvokeAgent(ctx context.Context, agentName, prompt string) (*cli.InvocationResult, error) {
+	agent, err := o.lookupAgent(o.registry.Snapshot(), agentName)
+	if err != nil {
+		return nil, err
+	}
+	return o.invoke(ctx, o.invokerFor(ctx), agent, prompt)
+}
+
+// lookupAgent returns the named agent if it is in agentSet and enabled.
+func (o *Orchestrator) lookupAgent(agentSet *agents.Snapshot, name string) (*agents.Agent, error) {
+	agent, err := agentSet.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if agent.Disabled {
+		return nil, fmt.Errorf("%w: %s", agents.ErrAgentDisabled, name)
+	}
+	return agent, nil
+}
+
+// invoke runs agent with invoker, passing the agent's timeout, retries,
+// model and output limit as cli.InvocationOptions, along with the
+// orchestrator's directory and the instructions of agents the Copilot CLI
+// cannot load by name.
+func (o *Orchestrator) invoke(ctx context.Context, invoker Invoker, agent *agents.Agent, prompt string) (*cli.InvocationResult, error) {
+	opts := cli.InvocationOptions{
+		Timeout:        agent.Timeout,
+		Retries:        agent.Retries,
+		Model:          agent.Model,
+		MaxOutputBytes: agent.MaxOutputBytes,
+		Dir:            o.dir,
+		Agent:          agent,
+	}
+	if !agent.CopilotAgent() {
+		opts.Instructions = agent.Instructions
+	}
+	return invoker.InvokeAgent(cli.WithInvocationOptions(ctx, opts), agent.Name, prompt)
+}
+
+// recordChain stores the outcome of executeChain on state. When the chain was
+// cancelled or timed out, the agent that was interrupted and the agents that
+// never started are listed in AbortedAgents.
+func (o *Orchestrator) recordChain(state *ContextState, chain []*agents.Agent, finalOutput string, results []cli.InvocationResult, err error) {
+	state.AgentResults = results
+	state.FinalOutput = finalOutput
+	state.Status = StatusCompleted
+
+	state.CompletedAgents = []string{}
+	for i, agent := range chain {
+		switch {
+		case i < len(results) && !results[i].Cancelled:
+			state.CompletedAgents = append(state.CompletedAgents, agent.Name)
+		case err != nil:
+			state.AbortedAgents = append(state.AbortedAgents, agent.Name)
+		}
+	}
+
+	if err == nil {
+		return
+	}
+
+	state.Status = StatusCancelled
+	if errors.Is(err, context.DeadlineExceeded) {
+		state.Status = StatusTimedOut
+	}
+	o.logger.Info("orchestration stopped",
+		zap.String("status", string(state.Status)),
+		zap.Strings("completed", state.CompletedAgents),
+		zap.Strings("aborted", state.AbortedAgents),
+		zap.Error(err),
+	)
+}
+
+// agentFinishMessage summarizes an agent result for progress reporting.
+func agentFinishMessage(result *cli.InvocationResult) string {
+	status := "succeeded"
+	if !result.Success {
+		status = "failed"
+	}
+	return fmt.Sprintf("%s %s in %s", result.Agent, status, result.Duration.Round(time.Millisecond))
+}
+
 // buildAgentPrompt constructs the prompt for an agent, including context.
 //
 // Context Accumulation Strategy:
@@ -186,7 +442,9 @@ func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents [
 // in the chain receives:
 //  1. The original/refined user prompt (base context)
 //  2. Agent-specific context (who they are, what they do)
-//  3. Results from all previous agents (accumulated context)
+//  3. The agent's instructions, when withInstructions is set because the
+//     invoker's backend does not load agent files itself
+//  4. Results from all previous agents (accumulated context)
 //
 // Prompt Structure:
 //
@@ -194,6 +452,9 @@ func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents [
 //
 //	[Agent Context: You are the <agent-name>. <agent-description>]
 //
+//	[Agent Instructions:]
+//	<instructions>
+//
 //	[Previous Agent Results:]
 //	- Agent 1 (<name>): <output>
 //	- Agent 2 (<name>): <output>
@@ -220,13 +481,18 @@ func (o *Orchestrator) executeChain(ctx context.Context, prompt string, agents [
 //   - Selective context: only pass relevant previous results
 //   - Context summarization: compress older results
 //   - Parallel execution: run independent agents concurrently
-func (o *Orchestrator) buildAgentPrompt(basePrompt string, agent *agents.Agent, contextState ContextState) string {
+func (o *Orchestrator) buildAgentPrompt(basePrompt string, agent *agents.Agent, contextState ContextState, withInstructions bool) string {
 	// Start with base prompt
 	agentPrompt := basePrompt
 
 	// Add agent-specific context to help the agent understand its role
 	agentPrompt += fmt.Sprintf("\n\n[Agent Context: You are the %s. %s]", agent.Name, agent.Description)
 
+	// Add the agent's own instructions for backends that cannot load them
+	if withInstructions && agent.Instructions != "" {
+		agentPrompt += "\n\n[Agent Instructions:]\n" + agent.Instructions
+	}
+
 	// Accumulate previous agent results to enable context flow
 	if len(contextState.AgentResults) > 0 {
 		agentPrompt += "\n\n[Previous Agent Results:]"
@@ -255,6 +521,8 @@ func (o *Orchestrator) synthesizeOutput(contextState ContextState, results []cli
 			if result.Output != nil {
 				output.WriteString(fmt.Sprintf("Output:\n%s\n\n", string(result.Output)))
 			}
+		} else if result.Cancelled {
+			output.WriteString(fmt.Sprintf("Status: ⊘ Cancelled\n\n"))
 		} else {
 			output.WriteString(fmt.Sprintf("Status: ✗ Failed\n"))
 			if result.Error != "" {
@@ -276,22 +544,27 @@ func (o *Orchestrator) synthesizeOutput(contextState ContextState, results []cli
 	return output.String()
 }
 
-// selectAgents selects agents based on keywords (keyword matching).
-func (o *Orchestrator) selectAgents(keywords []string, maxCount int) []*agents.Agent {
-	matched := o.registry.MatchKeywords(keywords)
+// selectAgents selects agents of agentSet based on keywords (keyword matching).
+func (o *Orchestrator) selectAgents(agentSet *agents.Snapshot, keywords []string, maxCount int) []*agents.Agent {
+	matched := agentSet.MatchKeywords(keywords)
 	if len(matched) > maxCount {
 		matched = matched[:maxCount]
 	}
 	return matched
 }
 
-// selectTopAgents selects the top N agents by default.
-func (o *Orchestrator) selectTopAgents(count int) []*agents.Agent {
-	agents := o.registry.All()
-	if len(agents) > count {
-		return agents[:count]
+// selectTopAgents selects the first N enabled agents of agentSet by default.
+func (o *Orchestrator) selectTopAgents(agentSet *agents.Snapshot, count int) []*agents.Agent {
+	selected := make([]*agents.Agent, 0, count)
+	for _, agent := range agentSet.All() {
+		if len(selected) == count {
+			break
+		}
+		if !agent.Disabled {
+			selected = append(selected, agent)
+		}
 	}
-	return agents
+	return selected
 }
 
 // extractKeywords extracts keywords from the prompt for agent selection.
@@ -300,7 +573,8 @@ func (o *Orchestrator) extractKeywords(p string) []string {
 }
 
 // buildRationale creates a human-readable rationale for agent selection.
-func (o *Orchestrator) buildRationale(keywords []string, selectedAgents []*agents.Agent) string {
+// chain is selectedAgents resolved with their dependencies.
+func (o *Orchestrator) buildRationale(keywords []string, selectedAgents, chain []*agents.Agent) string {
 	if len(selectedAgents) == 0 {
 		return "No agents matched the prompt keywords"
 	}
@@ -316,6 +590,20 @@ func (o *Orchestrator) buildRationale(keywords []string, selectedAgents []*agent
 		rationale.WriteString(agent.Name)
 	}
 
+	selected := make(map[string]bool, len(selectedAgents))
+	for _, agent := range selectedAgents {
+		selected[agent.Name] = true
+	}
+	var required []string
+	for _, agent := range chain {
+		if !selected[agent.Name] {
+			required = append(required, agent.Name)
+		}
+	}
+	if len(required) > 0 {
+		rationale.WriteString(". Required: " + strings.Join(required, ", "))
+	}
+
 	return rationale.String()
 }
 
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+func TestPlan(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Keywords: []string{"code-review", "quality"}})
+	registry.Add(&agents.Agent{Name: "test-generator", Keywords: []string{"test-generator", "testing"}})
+
+	tests := []struct {
+		name     string
+		registry *agents.Registry
+		prompt   string
+		want     error
+		status   RunStatus
+	}{
+		{"selects agents", registry, "Improve code quality of auth.go", nil, StatusPlanned},
+		{"empty prompt", registry, "  ", ErrInvalidPrompt, StatusFailed},
+		{"no agents", agents.NewRegistry(), "Improve code quality of auth.go", ErrOrchestrationFailed, StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A nil invoker would panic if Plan tried to run an agent.
+			orch := NewOrchestrator(tt.registry, nil, zap.NewNop())
+
+			state, err := orch.Plan(context.Background(), tt.prompt)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if state.Status != tt.status {
+				t.Errorf("expected status %q, got %q", tt.status, state.Status)
+			}
+			if tt.want != nil {
+				return
+			}
+
+			if len(state.SelectedAgents) == 0 || state.SelectedAgents[0] != "code-reviewer" {
+				t.Errorf("expected code-reviewer to be selected first, got %v", state.SelectedAgents)
+			}
+			if state.RefinedPrompt == "" || state.SelectionRationale == "" {
+				t.Errorf("expected refined prompt and rationale, got %+v", state)
+			}
+			if len(state.AgentResults) != 0 {
+				t.Errorf("expected no agent results, got %d", len(state.AgentResults))
+			}
+			if state.RegistryVersion != tt.registry.Version() {
+				t.Errorf("expected registry version %d, got %d", tt.registry.Version(), state.RegistryVersion)
+			}
+		})
+	}
+}
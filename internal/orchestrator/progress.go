@@ -0,0 +1,74 @@
+package orchestrator
+
+import "context"
+
+// ProgressStage identifies a step of an orchestration run.
+type ProgressStage string
+
+const (
+	// StageEvaluation is reported once the prompt has been evaluated.
+	StageEvaluation ProgressStage = "evaluation"
+	// StageSelection is reported once the agent chain has been chosen.
+	StageSelection ProgressStage = "selection"
+	// StageAgentStart is reported before each agent is invoked.
+	StageAgentStart ProgressStage = "agent_start"
+	// StageAgentFinish is reported after each agent returns.
+	StageAgentFinish ProgressStage = "agent_finish"
+	// StageSynthesis is reported once the final output has been synthesized.
+	StageSynthesis ProgressStage = "synthesis"
+)
+
+// ProgressEvent describes a single step of an orchestration run.
+type ProgressEvent struct {
+	Stage    ProgressStage `json:"stage"`
+	Step     int           `json:"step"`            // 1-based index of this event
+	Total    int           `json:"total,omitempty"` // Expected number of events in the run
+	Agent    string        `json:"agent,omitempty"`
+	Success  bool          `json:"success,omitempty"`
+	Duration int64         `json:"duration_ms,omitempty"` // Agent run time in milliseconds
+	Message  string        `json:"message"`
+}
+
+// ProgressFunc receives progress events. It is called synchronously from the
+// orchestration goroutine and should return quickly.
+type ProgressFunc func(ProgressEvent)
+
+type progressKey struct{}
+
+// WithProgress returns a context that reports orchestration progress to fn.
+// RunWithAuto and RunWithExplicitChain emit events at evaluation, selection,
+// each agent start and finish, and synthesis.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressTracker numbers the events of a single run and forwards them to
+// the ProgressFunc attached to the run's context, if any.
+type progressTracker struct {
+	fn    ProgressFunc
+	step  int
+	total int
+}
+
+// newProgressTracker creates a tracker for the ProgressFunc carried by ctx.
+func newProgressTracker(ctx context.Context) *progressTracker {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return &progressTracker{fn: fn}
+}
+
+// setAgentCount fixes the expected number of events once the chain is known:
+// evaluation, selection, a start and finish per agent, and synthesis.
+func (p *progressTracker) setAgentCount(n int) {
+	p.total = 3 + 2*n
+}
+
+// report numbers ev and forwards it to the ProgressFunc.
+func (p *progressTracker) report(ev ProgressEvent) {
+	if p.fn == nil {
+		return
+	}
+	p.step++
+	ev.Step = p.step
+	ev.Total = p.total
+	p.fn(ev)
+}
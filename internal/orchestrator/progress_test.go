@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+// newTestOrchestrator creates an orchestrator whose invoker cannot find the
+// copilot binary, so every agent fails fast without spawning a real process.
+func newTestOrchestrator(t *testing.T) *Orchestrator {
+	t.Helper()
+	t.Setenv("PATH", t.TempDir())
+
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Keywords: []string{"code-review", "quality"}})
+	registry.Add(&agents.Agent{Name: "test-generator", Keywords: []string{"test-generator", "testing"}})
+
+	logger := zap.NewNop()
+	return NewOrchestrator(registry, cli.NewInvoker(time.Second, logger), logger)
+}
+
+func TestRunWithExplicitChain_ReportsProgress(t *testing.T) {
+	orch := newTestOrchestrator(t)
+
+	var events []ProgressEvent
+	ctx := WithProgress(context.Background(), func(ev ProgressEvent) {
+		events = append(events, ev)
+	})
+
+	if _, err := orch.RunWithExplicitChain(ctx, "Review auth.go and add tests", []string{"code-reviewer", "test-generator"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ProgressStage{
+		StageEvaluation,
+		StageSelection,
+		StageAgentStart, StageAgentFinish,
+		StageAgentStart, StageAgentFinish,
+		StageSynthesis,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+
+	for i, ev := range events {
+		if ev.Stage != expected[i] {
+			t.Errorf("event %d: expected stage %q, got %q", i, expected[i], ev.Stage)
+		}
+		if ev.Step != i+1 {
+			t.Errorf("event %d: expected step %d, got %d", i, i+1, ev.Step)
+		}
+		if ev.Total != len(expected) {
+			t.Errorf("event %d: expected total %d, got %d", i, len(expected), ev.Total)
+		}
+	}
+
+	if events[3].Agent != "code-reviewer" || events[3].Success {
+		t.Errorf("expected failed code-reviewer finish event, got %+v", events[3])
+	}
+}
+
+func TestRunWithAuto_ReportsProgress(t *testing.T) {
+	orch := newTestOrchestrator(t)
+
+	var stages []ProgressStage
+	var totals []int
+	ctx := WithProgress(context.Background(), func(ev ProgressEvent) {
+		stages = append(stages, ev.Stage)
+		totals = append(totals, ev.Total)
+	})
+
+	state, err := orch.RunWithAuto(ctx, "Review the orchestrator code for quality issues")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedCount := 3 + 2*len(state.SelectedAgents)
+	if len(stages) != expectedCount {
+		t.Fatalf("expected %d events, got %d: %v", expectedCount, len(stages), stages)
+	}
+	if stages[0] != StageEvaluation || stages[len(stages)-1] != StageSynthesis {
+		t.Errorf("expected evaluation first and synthesis last, got %v", stages)
+	}
+	// The total is known from the first event on.
+	for i, total := range totals {
+		if total != expectedCount {
+			t.Errorf("event %d: expected total %d, got %d", i, expectedCount, total)
+		}
+	}
+}
+
+func TestRunWithAuto_NoProgressFunc(t *testing.T) {
+	orch := newTestOrchestrator(t)
+
+	// Runs without a ProgressFunc must not panic.
+	if _, err := orch.RunWithAuto(context.Background(), "Review the orchestrator code for quality issues"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// durationInvoker reports every agent as succeeding after duration.
+type durationInvoker struct {
+	duration time.Duration
+}
+
+func (d durationInvoker) InvokeAgent(_ context.Context, agentName, _ string) (*cli.InvocationResult, error) {
+	return &cli.InvocationResult{Agent: agentName, Success: true, Duration: d.duration}, nil
+}
+
+func TestProgressEvent_DurationMilliseconds(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Keywords: []string{"code-review"}})
+	orch := NewOrchestrator(registry, durationInvoker{1500 * time.Millisecond}, zap.NewNop())
+
+	var finish ProgressEvent
+	ctx := WithProgress(context.Background(), func(ev ProgressEvent) {
+		if ev.Stage == StageAgentFinish {
+			finish = ev
+		}
+	})
+	if _, err := orch.RunWithExplicitChain(ctx, "Review auth.go", []string{"code-reviewer"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if finish.Duration != 1500 {
+		t.Errorf("expected duration 1500ms, got %d", finish.Duration)
+	}
+	data, err := json.Marshal(finish)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"duration_ms":1500,`) {
+		t.Errorf("expected duration_ms of 1500 in %s", data)
+	}
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+// optionsInvoker records the invocation options of each call.
+type optionsInvoker struct {
+	options []cli.InvocationOptions
+}
+
+func (o *optionsInvoker) InvokeAgent(ctx context.Context, agentName, _ string) (*cli.InvocationResult, error) {
+	o.options = append(o.options, cli.InvocationOptionsFrom(ctx))
+	return &cli.InvocationResult{Agent: agentName, Success: true}, nil
+}
+
+func TestAgentSettings(t *testing.T) {
+	retries := 3
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{
+		Name:           "code-reviewer",
+		Keywords:       []string{"code-review", "quality"},
+		Timeout:        90 * time.Second,
+		Retries:        &retries,
+		Model:          "gpt-5",
+		MaxOutputBytes: 1024,
+	})
+	registry.Add(&agents.Agent{Name: "quality-gate", Keywords: []string{"quality"}, Disabled: true})
+	registry.Add(&agents.Agent{Name: "review-mode", Format: agents.FormatChatMode, Instructions: "Review carefully."})
+	registry.Add(&agents.Agent{Name: "repo-reviewer", Source: agents.SourceRepo, Instructions: "Review the repo."})
+	registry.Add(&agents.Agent{Name: "shared-reviewer", Source: agents.SourceShared, Instructions: "Review shared code."})
+	registry.Add(&agents.Agent{Name: "security/secret-scanner", Namespace: "security", Source: agents.SourceRepo, Instructions: "Find secrets."})
+
+	invoker := &optionsInvoker{}
+	orch := NewOrchestrator(registry, invoker, zap.NewNop())
+	orch.SetDir("/src/payments")
+
+	t.Run("options passed to invoker", func(t *testing.T) {
+		if _, err := orch.InvokeAgent(context.Background(), "code-reviewer", "Review auth.go"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := cli.InvocationOptions{Timeout: 90 * time.Second, Retries: &retries, Model: "gpt-5", MaxOutputBytes: 1024, Dir: "/src/payments", Agent: registry.Get("code-reviewer")}
+		if got := invoker.options[len(invoker.options)-1]; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected options %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("instructions of chat modes passed to invoker", func(t *testing.T) {
+		if _, err := orch.InvokeAgent(context.Background(), "review-mode", "Review auth.go"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := invoker.options[len(invoker.options)-1].Instructions; got != "Review carefully." {
+			t.Errorf("expected the chat mode's instructions, got %q", got)
+		}
+	})
+
+	t.Run("instructions of agents outside the repository passed to invoker", func(t *testing.T) {
+		want := map[string]string{
+			"repo-reviewer":   "", // The CLI loads it from .github/agents
+			"shared-reviewer": "Review shared code.",
+			// The CLI does not resolve namespaces
+			"security/secret-scanner": "Find secrets.",
+		}
+		for name, instructions := range want {
+			if _, err := orch.InvokeAgent(context.Background(), name, "Review auth.go"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := invoker.options[len(invoker.options)-1].Instructions; got != instructions {
+				t.Errorf("%s: expected instructions %q, got %q", name, instructions, got)
+			}
+		}
+	})
+
+	t.Run("disabled agent not selected", func(t *testing.T) {
+		state, err := orch.Plan(context.Background(), "Improve code quality of auth.go")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"code-reviewer"}; !reflect.DeepEqual(state.SelectedAgents, want) {
+			t.Errorf("expected %v, got %v", want, state.SelectedAgents)
+		}
+	})
+
+	t.Run("disabled agent cannot run", func(t *testing.T) {
+		if _, err := orch.InvokeAgent(context.Background(), "quality-gate", "Check"); !errors.Is(err, agents.ErrAgentDisabled) {
+			t.Errorf("expected ErrAgentDisabled, got %v", err)
+		}
+		if _, err := orch.RunWithExplicitChain(context.Background(), "Check", []string{"quality-gate"}); !errors.Is(err, agents.ErrAgentDisabled) {
+			t.Errorf("expected ErrAgentDisabled, got %v", err)
+		}
+	})
+}
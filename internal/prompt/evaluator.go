@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -224,6 +225,26 @@ func containsActionVerb(prompt string) bool {
 	return false
 }
 
+// domainKeywords maps prompt patterns to the agent keywords they produce.
+// Each pattern maps to agent capabilities.
+var domainKeywords = map[string][]string{
+	"code|review|quality|bug|issue|fix|check|error|performance|refactor|correct": {"code-review", "quality"},
+	"test|coverage|unit-test|mock|integration-test|edge-case":                    {"test-generator", "testing"},
+	"architecture|design|pattern|structure|organize|scale|module|boundary":       {"architecture-advisor", "design"},
+	"doc|readme|guide|comment|explain|write|api|tutorial":                        {"documentation-writer", "docs"},
+}
+
+// Keywords returns every keyword ExtractKeywords can produce, sorted. An
+// agent keyword outside this set never matches a prompt.
+func Keywords() []string {
+	var keywords []string
+	for _, kws := range domainKeywords {
+		keywords = append(keywords, kws...)
+	}
+	slices.Sort(keywords)
+	return slices.Compact(keywords)
+}
+
 // ExtractKeywords extracts potential keywords from the prompt for agent selection.
 //
 // Keyword Extraction Heuristics:
@@ -266,15 +287,6 @@ func containsActionVerb(prompt string) bool {
 func ExtractKeywords(prompt string) []string {
 	keywords := []string{}
 
-	// Domain-specific pattern matching
-	// Each pattern maps to agent capabilities
-	domainKeywords := map[string][]string{
-		"code|review|quality|bug|issue|fix|check|error|performance|refactor|correct": {"code-review", "quality"},
-		"test|coverage|unit-test|mock|integration-test|edge-case":                    {"test-generator", "testing"},
-		"architecture|design|pattern|structure|organize|scale|module|boundary":       {"architecture-advisor", "design"},
-		"doc|readme|guide|comment|explain|write|api|tutorial":                        {"documentation-writer", "docs"},
-	}
-
 	promptLower := strings.ToLower(prompt)
 	for pattern, kws := range domainKeywords {
 		re := regexp.MustCompile(pattern)
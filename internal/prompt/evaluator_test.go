@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -210,6 +211,24 @@ func TestExtractKeywords(t *testing.T) {
 	}
 }
 
+func TestKeywords(t *testing.T) {
+	keywords := Keywords()
+	if len(keywords) != 8 {
+		t.Errorf("expected 8 keywords, got %v", keywords)
+	}
+
+	// Every keyword a prompt produces is listed.
+	prompt := "Review the code, test it, design the module and document the API"
+	for _, kw := range ExtractKeywords(prompt) {
+		if !slices.Contains(keywords, kw) {
+			t.Errorf("keyword %q of ExtractKeywords missing from %v", kw, keywords)
+		}
+	}
+	if !slices.IsSorted(keywords) {
+		t.Errorf("expected sorted keywords, got %v", keywords)
+	}
+}
+
 // Note: containsActionVerb is package-private, tested indirectly through Evaluate
 
 func TestEvaluator_SuggestRefinement(t *testing.T) {
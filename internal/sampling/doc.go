@@ -0,0 +1,48 @@
+// Package sampling invokes agents through the connected MCP client's
+// sampling capability instead of the GitHub Copilot CLI.
+//
+// MCP clients that support sampling (sampling/createMessage) run an LLM on
+// the server's behalf. Using the client as the agent backend lets CopilotOS
+// work inside any sampling-capable client without the copilot binary
+// installed, and the user's client stays in control of model choice and
+// approval.
+//
+// This package handles:
+//   - Request Building: Turn an agent definition and prompt into a sampling request
+//   - Response Handling: Convert the sampled message into an InvocationResult
+//   - Timeouts and Cancellation: Bound each request and report cancellation
+//
+// # Request Structure
+//
+// Each invocation sends a single sampling/createMessage request:
+//
+//	systemPrompt: <agent instructions from the agent's Markdown body>
+//	              (or "You are the <name>. <description>" when it has none)
+//	messages:
+//	  - role: user
+//	    content: <prompt built by the orchestrator, including agent context
+//	              and previous agent results>
+//
+// # Results
+//
+// The sampled text becomes the InvocationResult output: JSON responses are
+// kept as JSON, anything else is stored as a JSON string, matching the CLI
+// backend. Failed requests return an error wrapping ErrSamplingFailed; clients
+// that did not declare the sampling capability are rejected up front with
+// ErrSamplingNotSupported.
+//
+// Usage Example
+//
+//	// Inside an MCP tool handler
+//	inv, err := sampling.NewInvoker(req.Session, registry, 5*time.Minute, logger)
+//	if err != nil {
+//	    return err // client cannot sample
+//	}
+//	ctx = orchestrator.WithInvoker(ctx, inv)
+//	state, err := orch.RunWithAuto(ctx, prompt)
+//
+// # Thread Safety
+//
+// An Invoker is safe for concurrent use; each call issues an independent
+// request on the session.
+package sampling
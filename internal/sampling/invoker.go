@@ -0,0 +1,187 @@
+package sampling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+// maxTokens is the response size requested from the client.
+const maxTokens = 4096
+
+var (
+	// ErrSamplingNotSupported is returned when the client did not declare
+	// the sampling capability.
+	ErrSamplingNotSupported = errors.New("client does not support sampling")
+
+	// ErrSamplingFailed is wrapped by errors returned from sampling requests.
+	ErrSamplingFailed = errors.New("sampling request failed")
+)
+
+// Supported reports whether the client of session declared the sampling
+// capability.
+func Supported(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
+}
+
+// Invoker runs agents by sending sampling requests to an MCP client.
+type Invoker struct {
+	session  *mcp.ServerSession
+	registry *agents.Registry
+	timeout  time.Duration
+	logger   *zap.Logger
+}
+
+// NewInvoker creates an invoker that samples through session. Agents not
+// given with cli.InvocationOptions are looked up in registry for their
+// instructions. It returns
+// ErrSamplingNotSupported if the client cannot sample.
+func NewInvoker(session *mcp.ServerSession, registry *agents.Registry, timeout time.Duration, logger *zap.Logger) (*Invoker, error) {
+	if !Supported(session) {
+		return nil, ErrSamplingNotSupported
+	}
+	return &Invoker{
+		session:  session,
+		registry: registry,
+		timeout:  timeout,
+		logger:   logger,
+	}, nil
+}
+
+// InvokeAgent asks the client to respond to prompt as the named agent.
+//
+// The agent, timeout, model and output limit of cli.InvocationOptions
+// attached to ctx are honored; the model is sent as a model preference hint. Sampling
+// requests are not retried, as a failure usually means the user declined.
+//
+// Like cli.Invoker.InvokeAgent, the returned InvocationResult is always
+// non-nil. Request failures are returned as errors wrapping
+// ErrSamplingFailed, timeouts wrap cli.ErrExecutionTimeout, and cancellation
+// sets Cancelled on the result.
+func (i *Invoker) InvokeAgent(ctx context.Context, agentName, prompt string) (*cli.InvocationResult, error) {
+	start := time.Now()
+	result := &cli.InvocationResult{
+		Agent:     agentName,
+		Timestamp: start,
+	}
+
+	opts := cli.InvocationOptionsFrom(ctx)
+	agent := opts.Agent
+	if agent == nil {
+		var err error
+		if agent, err = i.registry.Lookup(agentName); err != nil {
+			result.Error = err.Error()
+			return result, err
+		}
+	}
+
+	systemPrompt := buildSystemPrompt(agent)
+
+	timeout := i.timeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	} else if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	params := &mcp.CreateMessageParams{
+		SystemPrompt: systemPrompt,
+		Messages: []*mcp.SamplingMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: prompt},
+		}},
+		MaxTokens: maxTokens,
+		Metadata:  map[string]string{"agent": agentName},
+	}
+	if opts.Model != "" {
+		params.ModelPreferences = &mcp.ModelPreferences{Hints: []*mcp.ModelHint{{Name: opts.Model}}}
+	}
+
+	res, err := i.session.CreateMessage(ctx, params)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			result.Error = fmt.Sprintf("agent invocation timed out after %v", timeout)
+			err = fmt.Errorf("%w after %v", cli.ErrExecutionTimeout, timeout)
+		case context.Canceled:
+			result.Error = "agent invocation cancelled"
+			result.Cancelled = true
+			err = nil
+		default:
+			result.Error = err.Error()
+			err = fmt.Errorf("%w: %v", ErrSamplingFailed, err)
+		}
+		i.logger.Warn("agent sampling failed",
+			zap.String("agent", agentName),
+			zap.String("error", result.Error),
+		)
+		return result, err
+	}
+
+	text, ok := res.Content.(*mcp.TextContent)
+	if !ok {
+		result.Error = fmt.Sprintf("unsupported sampling response content %T", res.Content)
+		return result, fmt.Errorf("%w: %s", ErrSamplingFailed, result.Error)
+	}
+
+	output := text.Text
+	if opts.MaxOutputBytes > 0 && len(output) > opts.MaxOutputBytes {
+		// Cut at the limit without leaving half a UTF-8 character
+		output = strings.ToValidUTF8(output[:opts.MaxOutputBytes], "")
+		result.Truncated = true
+	}
+	result.Output = encodeOutput(output)
+	result.Success = true
+	i.logger.Debug("agent sampling succeeded",
+		zap.String("agent", agentName),
+		zap.String("model", res.Model),
+		zap.Duration("duration", result.Duration),
+	)
+	return result, nil
+}
+
+// buildSystemPrompt returns the agent's Markdown instructions, falling back
+// to its name and description when it has none.
+func buildSystemPrompt(agent *agents.Agent) string {
+	if agent.Instructions != "" {
+		return agent.Instructions
+	}
+	return strings.TrimSpace(fmt.Sprintf("You are the %s. %s", agent.Name, agent.Description))
+}
+
+// LoadsInstructions reports that the invoker sends each agent's instructions
+// as the system prompt, so the orchestrator does not repeat them in the
+// agent prompt.
+func (i *Invoker) LoadsInstructions() bool {
+	return true
+}
+
+// encodeOutput keeps JSON responses as JSON and encodes anything else as a
+// JSON string.
+func encodeOutput(text string) json.RawMessage {
+	trimmed := strings.TrimSpace(text)
+	if json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	encoded, _ := json.Marshal(trimmed)
+	return encoded
+}
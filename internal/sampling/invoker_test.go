@@ -0,0 +1,187 @@
+package sampling
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"go.uber.org/zap"
+)
+
+// connectSession connects a client configured with opts to a bare MCP server
+// and returns the server side of the session.
+func connectSession(t *testing.T, opts *mcp.ClientOptions) *mcp.ServerSession {
+	t.Helper()
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "test"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { clientSession.Close() })
+
+	return serverSession
+}
+
+// replyWith returns a sampling handler that answers with text and records
+// the request in got.
+func replyWith(text string, got **mcp.CreateMessageParams) func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		*got = req.Params
+		return &mcp.CreateMessageResult{
+			Content: &mcp.TextContent{Text: text},
+			Model:   "test-model",
+			Role:    "assistant",
+		}, nil
+	}
+}
+
+func TestInvoker_InvokeAgent(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Description: "Reviews code", Instructions: "Review Go code for bugs."})
+	registry.Add(&agents.Agent{Name: "test-generator", Description: "Generates tests"})
+
+	tests := []struct {
+		name         string
+		agent        string
+		reply        string
+		systemPrompt string
+		output       string
+	}{
+		{"instructions", "code-reviewer", "Looks good", "Review Go code for bugs.", `"Looks good"`},
+		{"description fallback", "test-generator", `{"tests":2}`, "You are the test-generator. Generates tests", `{"tests":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *mcp.CreateMessageParams
+			session := connectSession(t, &mcp.ClientOptions{CreateMessageHandler: replyWith(tt.reply, &got)})
+
+			inv, err := NewInvoker(session, registry, time.Second, zap.NewNop())
+			if err != nil {
+				t.Fatalf("NewInvoker failed: %v", err)
+			}
+
+			result, err := inv.InvokeAgent(context.Background(), tt.agent, "Review auth.go")
+			if err != nil {
+				t.Fatalf("InvokeAgent failed: %v", err)
+			}
+			if !result.Success || string(result.Output) != tt.output {
+				t.Errorf("unexpected result: success=%v output=%s", result.Success, result.Output)
+			}
+			if got.SystemPrompt != tt.systemPrompt {
+				t.Errorf("expected system prompt %q, got %q", tt.systemPrompt, got.SystemPrompt)
+			}
+			if text, ok := got.Messages[0].Content.(*mcp.TextContent); !ok || text.Text != "Review auth.go" {
+				t.Errorf("expected prompt as user message, got %+v", got.Messages[0].Content)
+			}
+		})
+	}
+}
+
+func TestInvoker_SamplingFailed(t *testing.T) {
+	session := connectSession(t, &mcp.ClientOptions{
+		CreateMessageHandler: func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return nil, errors.New("user rejected the request")
+		},
+	})
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer"})
+
+	inv, err := NewInvoker(session, registry, time.Second, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewInvoker failed: %v", err)
+	}
+
+	result, err := inv.InvokeAgent(context.Background(), "code-reviewer", "Review auth.go")
+	if !errors.Is(err, ErrSamplingFailed) {
+		t.Errorf("expected ErrSamplingFailed, got %v", err)
+	}
+	if result == nil || result.Success || result.Error == "" {
+		t.Errorf("expected failed result with error, got %+v", result)
+	}
+}
+
+func TestNewInvoker_NotSupported(t *testing.T) {
+	session := connectSession(t, nil)
+
+	if _, err := NewInvoker(session, agents.NewRegistry(), time.Second, zap.NewNop()); !errors.Is(err, ErrSamplingNotSupported) {
+		t.Errorf("expected ErrSamplingNotSupported, got %v", err)
+	}
+}
+
+func TestInvoker_InvocationOptions(t *testing.T) {
+	var got *mcp.CreateMessageParams
+	session := connectSession(t, &mcp.ClientOptions{CreateMessageHandler: replyWith("abcdefghij", &got)})
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer"})
+
+	inv, err := NewInvoker(session, registry, time.Second, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewInvoker failed: %v", err)
+	}
+
+	ctx := cli.WithInvocationOptions(context.Background(), cli.InvocationOptions{Model: "gpt-5", MaxOutputBytes: 4})
+	result, err := inv.InvokeAgent(ctx, "code-reviewer", "Review auth.go")
+	if err != nil {
+		t.Fatalf("InvokeAgent failed: %v", err)
+	}
+
+	if got.ModelPreferences == nil || len(got.ModelPreferences.Hints) != 1 || got.ModelPreferences.Hints[0].Name != "gpt-5" {
+		t.Errorf("expected model hint gpt-5, got %+v", got.ModelPreferences)
+	}
+	if string(result.Output) != `"abcd"` || !result.Truncated {
+		t.Errorf("expected truncated output \"abcd\", got %s (truncated=%v)", result.Output, result.Truncated)
+	}
+}
+
+func TestInvoker_PlannedAgent(t *testing.T) {
+	planned := &agents.Agent{Name: "code-reviewer", Instructions: "Review as planned."}
+
+	tests := []struct {
+		name     string
+		registry *agents.Registry
+	}{
+		{"changed since planning", func() *agents.Registry {
+			r := agents.NewRegistry()
+			r.Add(&agents.Agent{Name: "code-reviewer", Instructions: "Review as reloaded."})
+			return r
+		}()},
+		{"removed since planning", agents.NewRegistry()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *mcp.CreateMessageParams
+			session := connectSession(t, &mcp.ClientOptions{CreateMessageHandler: replyWith("Looks good", &got)})
+
+			inv, err := NewInvoker(session, tt.registry, time.Second, zap.NewNop())
+			if err != nil {
+				t.Fatalf("NewInvoker failed: %v", err)
+			}
+
+			// The agent of the invocation options wins over the registry.
+			ctx := cli.WithInvocationOptions(context.Background(), cli.InvocationOptions{Agent: planned})
+			if _, err := inv.InvokeAgent(ctx, "code-reviewer", "Review auth.go"); err != nil {
+				t.Fatalf("InvokeAgent failed: %v", err)
+			}
+			if got.SystemPrompt != "Review as planned." {
+				t.Errorf("expected the planned instructions, got %q", got.SystemPrompt)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/sampling"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// Backend selects how agents are invoked.
+type Backend string
+
+const (
+	// BackendCLI runs agents with the GitHub Copilot CLI.
+	BackendCLI Backend = "cli"
+	// BackendSampling runs agents through the client's sampling capability.
+	BackendSampling Backend = "sampling"
+	// BackendAuto uses the Copilot CLI when it is installed and falls back
+	// to sampling when the client supports it.
+	BackendAuto Backend = "auto"
+)
+
+// ParseBackend validates a backend name.
+func ParseBackend(name string) (Backend, bool) {
+	switch b := Backend(name); b {
+	case BackendCLI, BackendSampling, BackendAuto:
+		return b, true
+	}
+	return "", false
+}
+
+// agentInvoker returns the invoker for a tool call made over session against
+// repo. Agents run with the configured backend unless their frontmatter
+// selects another one.
+func (s *Server) agentInvoker(session *mcp.ServerSession, repo *workspace.Repo) (orchestrator.Invoker, error) {
+	inv, err := s.backendInvoker(s.backend, session, repo)
+	if err != nil {
+		return nil, err
+	}
+	return &agentBackends{server: s, session: session, repo: repo, fallback: inv}, nil
+}
+
+// backendInvoker returns the invoker of backend for a tool call made over
+// session against repo.
+func (s *Server) backendInvoker(backend Backend, session *mcp.ServerSession, repo *workspace.Repo) (orchestrator.Invoker, error) {
+	switch backend {
+	case BackendSampling:
+		return s.samplingInvoker(session, repo)
+	case BackendAuto:
+		if !s.invoker.Installed() && sampling.Supported(session) {
+			return s.samplingInvoker(session, repo)
+		}
+	}
+	return s.invoker, nil
+}
+
+// samplingInvoker creates a sampling invoker for session that reads agent
+// instructions from repo.
+func (s *Server) samplingInvoker(session *mcp.ServerSession, repo *workspace.Repo) (orchestrator.Invoker, error) {
+	inv, err := sampling.NewInvoker(session, repo.Registry, s.invoker.Timeout(), s.logger)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("using sampling backend", zap.String("session", session.ID()))
+	return inv, nil
+}
+
+// agentBackends runs each agent with the backend named in its frontmatter,
+// and agents without one with the backend of the request.
+type agentBackends struct {
+	server   *Server
+	session  *mcp.ServerSession
+	repo     *workspace.Repo
+	fallback orchestrator.Invoker
+}
+
+// InvokeAgent runs the named agent with its backend, taken from the agent
+// of cli.InvocationOptions when the orchestrator planned one. If that
+// backend is not usable for the session, a failed result is returned with
+// the error.
+func (b *agentBackends) InvokeAgent(ctx context.Context, agentName, prompt string) (*cli.InvocationResult, error) {
+	agent := cli.InvocationOptionsFrom(ctx).Agent
+	if agent == nil {
+		agent = b.repo.Registry.Get(agentName)
+	}
+
+	inv := b.fallback
+	if agent != nil && agent.Backend != "" && Backend(agent.Backend) != b.server.backend {
+		var err error
+		inv, err = b.server.backendInvoker(Backend(agent.Backend), b.session, b.repo)
+		if err != nil {
+			return &cli.InvocationResult{Agent: agentName, Error: err.Error(), Timestamp: time.Now()}, err
+		}
+	}
+	return inv.InvokeAgent(ctx, agentName, prompt)
+}
+
+// LoadsInstructions reports that the Copilot CLI and sampling backends both
+// supply agent instructions themselves.
+func (b *agentBackends) LoadsInstructions() bool {
+	return true
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+)
+
+// samplingClient answers every sampling request with "sampled: <prompt>".
+var samplingClient = &mcp.ClientOptions{
+	CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+		return &mcp.CreateMessageResult{
+			Content: &mcp.TextContent{Text: "sampled: " + prompt},
+			Model:   "test-model",
+			Role:    "assistant",
+		}, nil
+	},
+}
+
+func TestServer_Backends(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  Backend
+		client   *mcp.ClientOptions
+		wantCode ErrorCode // empty when the agent should run through sampling
+	}{
+		{"cli without copilot", BackendCLI, samplingClient, CodeCLINotAvailable},
+		{"sampling", BackendSampling, samplingClient, ""},
+		{"sampling without client support", BackendSampling, nil, CodeSamplingNotSupported},
+		{"auto falls back to sampling", BackendAuto, samplingClient, ""},
+		{"auto without client support", BackendAuto, nil, CodeCLINotAvailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServerWithOptions(t, testRegistry(), Options{Backend: tt.backend})
+			session := connectTestClientWithOptions(t, srv, tt.client)
+
+			var result cli.InvocationResult
+			res := callTool(t, session, "run_agent", map[string]any{
+				"agentName": "code-reviewer",
+				"prompt":    "Review auth.go",
+			}, &result)
+
+			if tt.wantCode != "" {
+				if !res.IsError {
+					t.Fatalf("expected tool error %s", tt.wantCode)
+				}
+				if te := decodeToolError(t, res); te.Code != tt.wantCode {
+					t.Errorf("expected code %s, got %s", tt.wantCode, te.Code)
+				}
+				return
+			}
+			if res.IsError || !result.Success || string(result.Output) != `"sampled: Review auth.go"` {
+				t.Errorf("expected sampled result, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestServer_RunWithOrchestrator_Sampling(t *testing.T) {
+	srv := newTestServerWithOptions(t, testRegistry(), Options{Backend: BackendSampling})
+	session := connectTestClientWithOptions(t, srv, samplingClient)
+
+	var state orchestrator.ContextState
+	callTool(t, session, "run_with_orchestrator", map[string]any{
+		"prompt": "Review auth.go",
+		"agents": []string{"code-reviewer", "test-generator"},
+	}, &state)
+
+	if len(state.AgentResults) != 2 {
+		t.Fatalf("expected 2 agent results, got %+v", state.AgentResults)
+	}
+	for _, result := range state.AgentResults {
+		if !result.Success {
+			t.Errorf("expected %s to succeed through sampling, got %q", result.Agent, result.Error)
+		}
+	}
+}
+
+func TestServer_AgentSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		agent    agents.Agent
+		wantCode ErrorCode // empty when the agent should run through sampling
+	}{
+		{"server backend", agents.Agent{Name: "code-reviewer"}, CodeCLINotAvailable},
+		{"agent backend", agents.Agent{Name: "code-reviewer", Backend: "sampling"}, ""},
+		{"disabled", agents.Agent{Name: "code-reviewer", Backend: "sampling", Disabled: true}, CodeAgentDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := agents.NewRegistry()
+			agent := tt.agent
+			registry.Add(&agent)
+
+			srv := newTestServerWithOptions(t, registry, Options{Backend: BackendCLI})
+			session := connectTestClientWithOptions(t, srv, samplingClient)
+
+			var result cli.InvocationResult
+			res := callTool(t, session, "run_agent", map[string]any{
+				"agentName": "code-reviewer",
+				"prompt":    "Review auth.go",
+			}, &result)
+
+			if tt.wantCode != "" {
+				if !res.IsError {
+					t.Fatalf("expected tool error %s", tt.wantCode)
+				}
+				if te := decodeToolError(t, res); te.Code != tt.wantCode {
+					t.Errorf("expected code %s, got %s", tt.wantCode, te.Code)
+				}
+				return
+			}
+			if res.IsError || !result.Success || string(result.Output) != `"sampled: Review auth.go"` {
+				t.Errorf("expected sampled result, got %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/diagnostics"
+)
+
+// DiagnoseInput holds the arguments of the diagnose tool.
+type DiagnoseInput struct{}
+
+// handleDiagnose reports the health of the Copilot CLI, the configuration,
+// and the agents of every served repository. A failing report is still a
+// successful tool call; its status field carries the outcome.
+func (s *Server) handleDiagnose(ctx context.Context, _ *mcp.CallToolRequest, _ DiagnoseInput) (*mcp.CallToolResult, any, error) {
+	return jsonResult(diagnostics.Run(ctx, diagnostics.Options{
+		Build: diagnostics.BuildInfo{
+			Version:   s.info.Version,
+			Commit:    s.info.Commit,
+			BuildTime: s.info.BuildTime,
+		},
+		Config:      s.config,
+		CLI:         s.invoker,
+		CLIRequired: s.backend == BackendCLI,
+		Repos:       s.workspace.All(),
+	}))
+}
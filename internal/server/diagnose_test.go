@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/diagnostics"
+)
+
+func TestServer_Diagnose(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend Backend
+		want    diagnostics.Status
+	}{
+		// The test servers have no copilot binary on PATH and their
+		// repository roots do not exist.
+		{"cli backend", BackendCLI, diagnostics.StatusFail},
+		{"sampling backend", BackendSampling, diagnostics.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServerWithOptions(t, testRegistry(), Options{Backend: tt.backend})
+			session := connectTestClient(t, srv)
+
+			var report diagnostics.Report
+			res := callTool(t, session, "diagnose", map[string]any{}, &report)
+			if res.IsError {
+				t.Fatal("expected diagnose to succeed even when checks fail")
+			}
+			if report.Status != tt.want {
+				t.Errorf("expected status %q, got %q", tt.want, report.Status)
+			}
+			if report.CLI.Installed || report.Build.Version != "test" {
+				t.Errorf("unexpected CLI or build details: %+v %+v", report.CLI, report.Build)
+			}
+			if len(report.Repos) != 1 || len(report.Repos[0].Agents) != 2 {
+				t.Errorf("expected the test repository with 2 agents, got %+v", report.Repos)
+			}
+
+			wantCLI := diagnostics.StatusFail
+			if tt.backend != BackendCLI {
+				wantCLI = diagnostics.StatusWarn
+			}
+			for _, check := range report.Checks {
+				if check.Name == "copilot_cli" && check.Status != wantCLI {
+					t.Errorf("expected copilot_cli %q, got %q", wantCLI, check.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package server exposes the CopilotOS orchestrator as a Model Context
+// Protocol (MCP) server.
+//
+// This package handles:
+//   - Tool Registration: Registers the orchestrator tools on an MCP server
+//   - Request Handling: Decodes tool arguments and dispatches to the orchestrator
+//   - Result Encoding: Returns orchestration results as JSON tool content
+//   - Agent Resources: Publishes each discovered agent as an MCP resource
+//   - Agent Prompts: Publishes each discovered agent as an MCP prompt template
+//   - Transport: Serves MCP sessions over stdio or streamable HTTP
+//   - Session Tracking: Keeps per-client state for each connected session
+//   - Multiple Repositories: Serves the agents of every repository in a workspace
+//
+// # MCP Tools
+//
+// The server registers the following tools:
+//
+//	run_with_orchestrator - Evaluate a prompt, select agents, and execute the chain
+//	list_agents           - List all discovered agents and their keywords
+//	run_agent             - Run a single agent directly, bypassing the orchestrator
+//	evaluate_prompt       - Evaluate prompt clarity and preview the agent chain
+//	start_orchestration   - Start run_with_orchestrator as a background job
+//	get_job_status        - Get a job's status and latest progress
+//	get_job_result        - Get the result of a finished job
+//	cancel_job            - Cancel a queued or running job
+//	list_jobs             - List retained jobs, newest first
+//	list_repos            - List the repositories the server hosts
+//	diagnose              - Report Copilot CLI, configuration, and discovery health
+//	lint_agents           - Check agent files for errors and likely mistakes
+//
+// When run_with_orchestrator receives an explicit list of agents, the
+// orchestrator runs them in the given order instead of selecting agents
+// automatically. If the client supplies a progress token, each orchestration
+// step is forwarded as a notifications/progress message.
+//
+// # Repositories
+//
+// One server can host several repositories, each with its own agent registry
+// and orchestrator held in a workspace.Workspace. Every tool that works with
+// agents accepts an optional repo argument naming the repository, as listed
+// by list_repos; when it is omitted the first (default) repository is used.
+// list_jobs accepts repo as a filter. Unknown names fail with REPO_NOT_FOUND.
+//
+// # Client Roots
+//
+// When a client session starts, the server asks it for its roots
+// (roots/list) and serves each file root as a repository, so editors get the
+// agents of the folders they have open regardless of the server's working
+// directory. Options.Roots decides how these combine with the repositories
+// the workspace was created with: RootsMerge serves both; RootsReplace serves
+// only client roots while any client provides some; RootsOff ignores them.
+// Unless roots are ignored, a tool call without a repository uses the
+// repository of its session's first root, so several clients sharing a
+// server over HTTP each default to their own folder. Agent resources and
+// prompts are listed alike to every client, so they keep the first served
+// repository as their default. On notifications/roots/list_changed the
+// roots are reread and their agents rediscovered, and a session's roots are
+// dropped when it ends. Agent resources and prompts are republished after
+// every change.
+//
+// # Asynchronous Jobs
+//
+// Long chains can exceed an MCP client's request timeout. start_orchestration
+// takes the same arguments as run_with_orchestrator but returns a job ID at
+// once; clients poll get_job_status and fetch the ContextState with
+// get_job_result when the job is completed, failed, or cancelled. Jobs are
+// run by a jobs.Manager, which bounds how many run concurrently and drops
+// finished jobs after a retention period.
+//
+// # Agent Backends
+//
+// Options.Backend selects how tool calls invoke agents. BackendCLI runs the
+// GitHub Copilot CLI. BackendSampling sends each agent prompt back to the
+// requesting client as sampling/createMessage, so copilot-os works in any
+// sampling-capable client without the CLI installed. BackendAuto uses the CLI
+// when copilot is on PATH and sampling otherwise. The backend is chosen per
+// request and attached to the orchestration with orchestrator.WithInvoker.
+// An agent whose frontmatter names a backend runs with that backend instead.
+//
+// # Cancellation
+//
+// When a client sends notifications/cancelled for an in-flight tool call, the
+// MCP SDK cancels the handler's context. The running Copilot CLI process is
+// killed, no further agents are started, and run_with_orchestrator returns the
+// partial state with status "cancelled" and the completed and aborted agents.
+// A run whose context deadline passes returns the same with status
+// "timed_out".
+//
+// # Schemas
+//
+// Tools returns the tool definitions with input and output JSON Schemas
+// inferred from the handler types using github.com/google/jsonschema-go.
+// Successful results carry the output value as structuredContent, validating
+// against the advertised outputSchema, and as JSON text content. Error
+// results carry their payload as text content only. The
+// copilot-os schema command prints the same definitions.
+//
+// # Errors
+//
+// Failed tool calls return an error result (isError) whose content is a JSON
+// payload with a stable code, a message, and optional details:
+//
+//	{
+//	  "status": "error",
+//	  "error": {
+//	    "code": "AGENT_NOT_FOUND",
+//	    "message": "agent \"nonexistent\" not found",
+//	    "details": "available agents: code-reviewer, test-generator"
+//	  }
+//	}
+//
+// Codes are derived with errors.Is from the sentinel errors of the agents,
+// cli, and orchestrator packages: AGENT_NOT_FOUND, AGENT_DISABLED,
+// DEPENDENCY_CYCLE, CLI_NOT_AVAILABLE, EXECUTION_TIMEOUT, INVALID_PROMPT,
+// ORCHESTRATION_FAILED, JOB_NOT_FOUND, JOB_NOT_FINISHED, REPO_NOT_FOUND,
+// SAMPLING_NOT_SUPPORTED, and UNKNOWN_ERROR for anything else.
+//
+// # MCP Resources
+//
+// Every agent in the registry is published as a resource named after the
+// agent, e.g. agent://code-reviewer. Reading it returns the agent's Markdown
+// definition (frontmatter and instructions) followed by its parsed metadata
+// as JSON. Agents of other repositories than the default one carry the
+// repository as a query parameter, e.g. agent://index-tuner?repo=search. The
+// agent://{+name}{?repo} resource template resolves the same content by name,
+// including namespaced names such as agent://security/secret-scanner.
+// Call SyncAgents after a registry changes; clients are notified with
+// notifications/resources/list_changed.
+//
+// # MCP Prompts
+//
+// Every agent is also published as an MCP prompt of the same name, which
+// clients typically surface as a slash command (/code-reviewer). Prompts take
+// a task argument plus any arguments declared in the agent's frontmatter:
+//
+//	arguments: [file, focus]
+//
+// Getting a prompt returns a single user message with the agent's role,
+// its Markdown instructions, and the supplied argument values. Prompt names
+// are shared by all repositories; when the server hosts more than one, every
+// prompt also takes a repo argument, and an agent defined in several
+// repositories is taken from the first unless repo says otherwise. SyncAgents
+// refreshes prompts too, notifying clients with
+// notifications/prompts/list_changed.
+//
+// Usage Example
+//
+//	// Build the server from already-initialized components
+//	srv := server.New(ws, invoker, jobManager, server.Options{
+//	    Info: server.Info{Version: "1.0.0"},
+//	}, logger)
+//
+//	// Serve a single client over stdin/stdout until ctx is cancelled
+//	if err := srv.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// # Transports
+//
+// Run serves a single client over stdin/stdout, which means one server
+// process per editor session. RunHTTP serves the MCP streamable HTTP
+// transport at /mcp, so one long-lived server can be shared by many clients:
+//
+//	copilot-os serve --listen 127.0.0.1:8080
+//
+// Each HTTP client gets its own MCP session (identified by the
+// Mcp-Session-Id header) with the same tool set as stdio. Sessions that stay
+// idle longer than the configured timeout are closed.
+//
+// # Logging
+//
+// The stdio transport uses stdout for protocol messages, so all logging must
+// go to stderr. The zap loggers created by cmd/server write to stderr.
+//
+// # Thread Safety
+//
+// The Server is safe for concurrent use. Tool handlers may be invoked
+// concurrently by the MCP SDK.
+package server
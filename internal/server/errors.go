@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/sampling"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+)
+
+// ErrorCode identifies the class of a failed tool call.
+type ErrorCode string
+
+const (
+	// CodeAgentNotFound means a requested agent is not in the registry.
+	CodeAgentNotFound ErrorCode = "AGENT_NOT_FOUND"
+	// CodeAgentDisabled means a requested agent sets enabled: false.
+	CodeAgentDisabled ErrorCode = "AGENT_DISABLED"
+	// CodeDependencyCycle means the requires, after and before declarations
+	// of the chain's agents form a cycle.
+	CodeDependencyCycle ErrorCode = "DEPENDENCY_CYCLE"
+	// CodeCLINotAvailable means the Copilot CLI is not installed or not on PATH.
+	CodeCLINotAvailable ErrorCode = "CLI_NOT_AVAILABLE"
+	// CodeExecutionTimeout means agent execution exceeded its timeout.
+	CodeExecutionTimeout ErrorCode = "EXECUTION_TIMEOUT"
+	// CodeInvalidPrompt means the prompt failed validation.
+	CodeInvalidPrompt ErrorCode = "INVALID_PROMPT"
+	// CodeOrchestrationFailed means the orchestration process failed.
+	CodeOrchestrationFailed ErrorCode = "ORCHESTRATION_FAILED"
+	// CodeJobNotFound means a job ID is unknown or its job has expired.
+	CodeJobNotFound ErrorCode = "JOB_NOT_FOUND"
+	// CodeJobNotFinished means the result of a queued or running job was requested.
+	CodeJobNotFinished ErrorCode = "JOB_NOT_FINISHED"
+	// CodeRepoNotFound means a requested repository is not served.
+	CodeRepoNotFound ErrorCode = "REPO_NOT_FOUND"
+	// CodeSamplingNotSupported means the sampling backend was selected but the
+	// client did not declare the sampling capability.
+	CodeSamplingNotSupported ErrorCode = "SAMPLING_NOT_SUPPORTED"
+	// CodeUnknown is used for errors that match none of the other codes.
+	CodeUnknown ErrorCode = "UNKNOWN_ERROR"
+)
+
+// ToolError is the structured payload of a failed tool call.
+type ToolError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Details string    `json:"details,omitempty"`
+}
+
+// errorResponse wraps a ToolError in the documented error response shape.
+type errorResponse struct {
+	Status string    `json:"status"`
+	Error  ToolError `json:"error"`
+}
+
+// errorCode maps err to its ErrorCode by matching the sentinel errors of the
+// agents, cli, and orchestrator packages.
+func errorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, agents.ErrAgentNotFound):
+		return CodeAgentNotFound
+	case errors.Is(err, agents.ErrAgentDisabled):
+		return CodeAgentDisabled
+	case errors.Is(err, agents.ErrDependencyCycle):
+		return CodeDependencyCycle
+	case errors.Is(err, cli.ErrCLINotAvailable):
+		return CodeCLINotAvailable
+	case errors.Is(err, cli.ErrExecutionTimeout), errors.Is(err, context.DeadlineExceeded):
+		return CodeExecutionTimeout
+	case errors.Is(err, orchestrator.ErrInvalidPrompt):
+		return CodeInvalidPrompt
+	case errors.Is(err, orchestrator.ErrOrchestrationFailed):
+		return CodeOrchestrationFailed
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return CodeJobNotFound
+	case errors.Is(err, jobs.ErrJobNotFinished):
+		return CodeJobNotFinished
+	case errors.Is(err, workspace.ErrRepoNotFound), errors.Is(err, workspace.ErrNoRepos):
+		return CodeRepoNotFound
+	case errors.Is(err, sampling.ErrSamplingNotSupported):
+		return CodeSamplingNotSupported
+	default:
+		return CodeUnknown
+	}
+}
+
+// newToolError builds the ToolError for err, adding details that help the
+// client recover. repo is the repository the call was made against; nil
+// selects the default repository.
+func (s *Server) newToolError(repo *workspace.Repo, err error) ToolError {
+	te := ToolError{
+		Code:    errorCode(err),
+		Message: err.Error(),
+	}
+
+	switch te.Code {
+	case CodeAgentNotFound:
+		if repo == nil {
+			repo, _ = s.workspace.Get("")
+		}
+		names := make([]string, 0)
+		if repo != nil {
+			for _, agent := range repo.Registry.All() {
+				names = append(names, agent.Name)
+			}
+		}
+		te.Details = "available agents: " + strings.Join(names, ", ")
+	case CodeRepoNotFound:
+		names := make([]string, 0)
+		for _, r := range s.workspace.All() {
+			names = append(names, r.Name)
+		}
+		te.Details = "available repositories: " + strings.Join(names, ", ")
+	case CodeAgentDisabled:
+		te.Details = "remove enabled: false from the agent's frontmatter to run it"
+	case CodeDependencyCycle:
+		te.Details = "remove one of the requires, after or before entries that form the cycle"
+	case CodeCLINotAvailable:
+		te.Details = "install the GitHub Copilot CLI and make sure copilot is on PATH"
+	case CodeExecutionTimeout:
+		te.Details = "increase COPILOT_CLI_TIMEOUT or the agent's timeout: frontmatter setting, or narrow the prompt"
+	case CodeSamplingNotSupported:
+		te.Details = "use a client with sampling support or set AGENT_BACKEND=cli"
+	case CodeJobNotFinished:
+		te.Details = "poll get_job_status until the job is completed, failed, or cancelled"
+	}
+
+	return te
+}
+
+// toolError returns err as an error tool result whose text content carries a
+// ToolError. It has no structured content, which would have to validate
+// against the tool's outputSchema for successful results.
+func (s *Server) toolError(err error) (*mcp.CallToolResult, any, error) {
+	return s.repoToolError(nil, err)
+}
+
+// repoToolError is like toolError for a call made against repo, so that
+// details such as the available agents refer to that repository.
+func (s *Server) repoToolError(repo *workspace.Repo, err error) (*mcp.CallToolResult, any, error) {
+	resp := errorResponse{Status: "error", Error: s.newToolError(repo, err)}
+	data, encErr := json.MarshalIndent(resp, "", "  ")
+	if encErr != nil {
+		return nil, nil, fmt.Errorf("failed to encode error: %w", encErr)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		IsError: true,
+	}, nil, nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+)
+
+// decodeToolError extracts the ToolError from an error tool result.
+func decodeToolError(t *testing.T, res *mcp.CallToolResult) ToolError {
+	t.Helper()
+
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected *mcp.TextContent, got %T", res.Content[0])
+	}
+	var resp errorResponse
+	if err := json.Unmarshal([]byte(text.Text), &resp); err != nil {
+		t.Fatalf("failed to decode error payload: %v", err)
+	}
+	if resp.Status != "error" {
+		t.Errorf("expected status \"error\", got %q", resp.Status)
+	}
+	// structuredContent would have to match the tool's outputSchema.
+	if res.StructuredContent != nil {
+		t.Errorf("expected no structuredContent in an error result, got %v", res.StructuredContent)
+	}
+	return resp.Error
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"agent not found", &agents.NotFoundError{Name: "x"}, CodeAgentNotFound},
+		{"wrapped agent not found", fmt.Errorf("chain: %w", &agents.NotFoundError{Name: "x"}), CodeAgentNotFound},
+		{"agent disabled", fmt.Errorf("%w: x", agents.ErrAgentDisabled), CodeAgentDisabled},
+		{"dependency cycle", &agents.CycleError{Path: []string{"a", "b", "a"}}, CodeDependencyCycle},
+		{"cli not available", fmt.Errorf("%w: exec: not found", cli.ErrCLINotAvailable), CodeCLINotAvailable},
+		{"execution timeout", cli.ErrExecutionTimeout, CodeExecutionTimeout},
+		{"deadline exceeded", context.DeadlineExceeded, CodeExecutionTimeout},
+		{"invalid prompt", orchestrator.ErrInvalidPrompt, CodeInvalidPrompt},
+		{"orchestration failed", fmt.Errorf("%w: no agents", orchestrator.ErrOrchestrationFailed), CodeOrchestrationFailed},
+		{"unknown", errors.New("boom"), CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_RunAgent_CLINotAvailable(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res := callTool(t, session, "run_agent", map[string]any{
+		"agentName": "code-reviewer",
+		"prompt":    "Review auth.go",
+	}, nil)
+
+	if !res.IsError {
+		t.Fatal("expected tool error when the Copilot CLI is missing")
+	}
+	te := decodeToolError(t, res)
+	if te.Code != CodeCLINotAvailable {
+		t.Errorf("expected code %s, got %s", CodeCLINotAvailable, te.Code)
+	}
+	if !strings.Contains(te.Details, "PATH") {
+		t.Errorf("expected install hint in details, got %q", te.Details)
+	}
+}
+
+func TestServer_ExecutionTimeoutDetails(t *testing.T) {
+	te := newTestServer(t).newToolError(nil, cli.ErrExecutionTimeout)
+	for _, setting := range []string{"COPILOT_CLI_TIMEOUT", "timeout:"} {
+		if !strings.Contains(te.Details, setting) {
+			t.Errorf("expected details to name %s, got %q", setting, te.Details)
+		}
+	}
+}
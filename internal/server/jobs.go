@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"go.uber.org/zap"
+)
+
+// JobInput holds the arguments of the job tools that address a single job.
+type JobInput struct {
+	JobID string `json:"jobId" jsonschema:"ID returned by start_orchestration"`
+}
+
+// ListJobsInput holds the arguments of the list_jobs tool.
+type ListJobsInput struct {
+	Repo string `json:"repo,omitempty" jsonschema:"only list jobs of this repository"`
+}
+
+// JobResultOutput is the result of the get_job_result tool.
+type JobResultOutput struct {
+	Job    jobs.Job                   `json:"job"`
+	Result *orchestrator.ContextState `json:"result"` // Partial for cancelled jobs
+}
+
+// ListJobsOutput is the result of the list_jobs tool.
+type ListJobsOutput struct {
+	Jobs  []jobs.Job `json:"jobs"`
+	Count int        `json:"count"`
+}
+
+// handleStartOrchestration queues an orchestration job and returns it
+// without waiting for the chain to run.
+func (s *Server) handleStartOrchestration(ctx context.Context, req *mcp.CallToolRequest, in RunWithOrchestratorInput) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Prompt) == "" {
+		return s.toolError(orchestrator.ErrInvalidPrompt)
+	}
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+	for _, name := range in.Agents {
+		if _, err := repo.Registry.Lookup(name); err != nil {
+			return s.repoToolError(repo, err)
+		}
+	}
+
+	invoker, err := s.agentInvoker(req.Session, repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	job, err := s.jobs.Start(orchestrator.WithInvoker(ctx, invoker), repo.Name, in.Prompt, in.Agents)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	s.logger.Info("start_orchestration called",
+		zap.String("job_id", job.ID),
+		zap.String("repo", repo.Name),
+		zap.Strings("agents", in.Agents),
+	)
+	return jsonResult(job)
+}
+
+// handleGetJobStatus returns the current state of a job.
+func (s *Server) handleGetJobStatus(_ context.Context, _ *mcp.CallToolRequest, in JobInput) (*mcp.CallToolResult, any, error) {
+	job, err := s.jobs.Status(in.JobID)
+	if err != nil {
+		return s.toolError(err)
+	}
+	return jsonResult(job)
+}
+
+// handleGetJobResult returns the orchestration state of a finished job.
+func (s *Server) handleGetJobResult(_ context.Context, _ *mcp.CallToolRequest, in JobInput) (*mcp.CallToolResult, any, error) {
+	result, job, err := s.jobs.Result(in.JobID)
+	if err != nil {
+		return s.toolError(err)
+	}
+	return jsonResult(JobResultOutput{Job: job, Result: result})
+}
+
+// handleCancelJob cancels a queued or running job.
+func (s *Server) handleCancelJob(_ context.Context, _ *mcp.CallToolRequest, in JobInput) (*mcp.CallToolResult, any, error) {
+	job, err := s.jobs.Cancel(in.JobID)
+	if err != nil {
+		return s.toolError(err)
+	}
+	return jsonResult(job)
+}
+
+// handleListJobs lists all retained jobs, optionally only those of one
+// repository.
+func (s *Server) handleListJobs(_ context.Context, _ *mcp.CallToolRequest, in ListJobsInput) (*mcp.CallToolResult, any, error) {
+	all := s.jobs.List()
+	if in.Repo != "" {
+		filtered := make([]jobs.Job, 0, len(all))
+		for _, job := range all {
+			if job.Repo == in.Repo {
+				filtered = append(filtered, job)
+			}
+		}
+		all = filtered
+	}
+	return jsonResult(ListJobsOutput{
+		Jobs:  all,
+		Count: len(all),
+	})
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+)
+
+func TestServer_OrchestrationJob(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	var job jobs.Job
+	res := callTool(t, session, "start_orchestration", map[string]any{
+		"prompt": "Review auth.go",
+		"agents": []string{"code-reviewer"},
+	}, &job)
+	if res.IsError || job.ID == "" {
+		t.Fatalf("expected a job ID, got %+v", res.Content)
+	}
+	if job.Repo != "app" {
+		t.Errorf("expected job in the default repository, got %q", job.Repo)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Status.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish, last status %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+		callTool(t, session, "get_job_status", map[string]any{"jobId": job.ID}, &job)
+	}
+
+	var out JobResultOutput
+	callTool(t, session, "get_job_result", map[string]any{"jobId": job.ID}, &out)
+	if out.Job.Status != jobs.StatusCompleted {
+		t.Errorf("expected completed job, got %s (%s)", out.Job.Status, out.Job.Error)
+	}
+	if out.Result == nil || out.Result.Status != orchestrator.StatusCompleted || len(out.Result.AgentResults) != 1 {
+		t.Errorf("unexpected job result: %+v", out.Result)
+	}
+
+	var list ListJobsOutput
+	callTool(t, session, "list_jobs", map[string]any{}, &list)
+	if list.Count != 1 || list.Jobs[0].ID != job.ID {
+		t.Errorf("expected list_jobs to return the job, got %+v", list)
+	}
+
+	callTool(t, session, "list_jobs", map[string]any{"repo": "search"}, &list)
+	if list.Count != 0 {
+		t.Errorf("expected no jobs in another repository, got %+v", list)
+	}
+}
+
+func TestServer_JobErrors(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want ErrorCode
+	}{
+		{"status of unknown job", "get_job_status", map[string]any{"jobId": "job-missing"}, CodeJobNotFound},
+		{"result of unknown job", "get_job_result", map[string]any{"jobId": "job-missing"}, CodeJobNotFound},
+		{"cancel unknown job", "cancel_job", map[string]any{"jobId": "job-missing"}, CodeJobNotFound},
+		{"start with empty prompt", "start_orchestration", map[string]any{"prompt": ""}, CodeInvalidPrompt},
+		{"start with unknown agent", "start_orchestration", map[string]any{"prompt": "Review", "agents": []string{"nonexistent"}}, CodeAgentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := callTool(t, session, tt.tool, tt.args, nil)
+			if !res.IsError {
+				t.Fatal("expected tool error")
+			}
+			if te := decodeToolError(t, res); te.Code != tt.want {
+				t.Errorf("expected code %s, got %s", tt.want, te.Code)
+			}
+		})
+	}
+}
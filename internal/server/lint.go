@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+// LintAgentsInput holds the arguments of the lint_agents tool.
+type LintAgentsInput struct {
+	Repo string `json:"repo,omitempty" jsonschema:"repository whose agent files to check; defaults to the first repository"`
+}
+
+// LintAgentsOutput is the result of the lint_agents tool.
+type LintAgentsOutput struct {
+	Repo string `json:"repo"`
+	agents.LintReport
+}
+
+// handleLintAgents checks the agent files of a repository and of the shared
+// search paths. Like diagnose, a report with errors is still a successful
+// tool call; its errors count carries the outcome.
+func (s *Server) handleLintAgents(_ context.Context, req *mcp.CallToolRequest, in LintAgentsInput) (*mcp.CallToolResult, any, error) {
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	report, err := s.workspace.Lint(repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+	return jsonResult(LintAgentsOutput{Repo: repo.Name, LintReport: *report})
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+func TestServer_LintAgents(t *testing.T) {
+	// Without client roots, the repository added below is served whatever
+	// the test client reports.
+	srv := newTestServerWithOptions(t, testRegistry(), Options{Roots: RootsOff})
+	root := writeAgentRepo(t, "payments", "code-reviewer")
+	if err := os.WriteFile(filepath.Join(root, ".github", "agents", "broken.md"), []byte("# No frontmatter\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.workspace.Add(root); err != nil {
+		t.Fatal(err)
+	}
+	session := connectTestClient(t, srv)
+
+	tests := []struct {
+		name     string
+		repo     string
+		files    int
+		errors   int
+		warnings int
+	}{
+		// The default test repository does not exist on disk.
+		{"default repository", "", 0, 0, 0},
+		// broken.md has no frontmatter; keyword test never matches.
+		{"repository with issues", "payments", 2, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out LintAgentsOutput
+			res := callTool(t, session, "lint_agents", map[string]any{"repo": tt.repo}, &out)
+			if res.IsError {
+				t.Fatal("expected lint_agents to succeed even when files have errors")
+			}
+			if out.Files != tt.files || out.Errors != tt.errors || out.Warnings != tt.warnings {
+				t.Errorf("expected %d files, %d errors and %d warnings, got %+v", tt.files, tt.errors, tt.warnings, out)
+			}
+			for _, issue := range out.Issues {
+				if issue.Severity == agents.LintError && filepath.Base(issue.File) != "broken.md" {
+					t.Errorf("unexpected error %+v", issue)
+				}
+			}
+		})
+	}
+
+	res := callTool(t, session, "lint_agents", map[string]any{"repo": "missing"}, nil)
+	if !res.IsError {
+		t.Fatal("expected an error for an unknown repository")
+	}
+	if toolErr := decodeToolError(t, res); toolErr.Code != CodeRepoNotFound {
+		t.Errorf("expected %s, got %s", CodeRepoNotFound, toolErr.Code)
+	}
+}
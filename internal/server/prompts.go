@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+const (
+	// taskArgument is the prompt argument every agent prompt accepts.
+	taskArgument = "task"
+
+	// repoArgument selects the repository of an agent prompt when the
+	// server hosts more than one repository.
+	repoArgument = "repo"
+)
+
+// syncAgentPrompts brings the published agent prompts in line with the
+// registries of every repository. Prompt names are not scoped to a
+// repository, so an agent defined in several repositories is published once,
+// taking its definition from the first of them. Adding or removing prompts
+// notifies connected clients with notifications/prompts/list_changed.
+func (s *Server) syncAgentPrompts() {
+	s.promptsMu.Lock()
+	defer s.promptsMu.Unlock()
+
+	repos := s.workspace.All()
+	current := make(map[string]bool)
+	for _, repo := range repos {
+		for _, agent := range repo.Registry.All() {
+			if current[agent.Name] {
+				continue
+			}
+			current[agent.Name] = true
+
+			prompt := agentPrompt(agent)
+			if len(repos) > 1 {
+				prompt.Arguments = append(prompt.Arguments, &mcp.PromptArgument{
+					Name:        repoArgument,
+					Description: "Repository to take the agent from (defaults to the first that defines it)",
+				})
+			}
+			s.mcp.AddPrompt(prompt, s.getAgentPrompt)
+		}
+	}
+
+	var stale []string
+	for name := range s.agentPrompts {
+		if !current[name] {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		s.mcp.RemovePrompts(stale...)
+	}
+
+	s.agentPrompts = current
+	s.logger.Debug("agent prompts synced",
+		zap.Int("count", len(current)),
+		zap.Int("removed", len(stale)),
+	)
+}
+
+// agentPrompt builds the MCP prompt definition for an agent. Every prompt
+// takes a task argument, followed by the arguments declared in the agent's
+// frontmatter.
+func agentPrompt(agent *agents.Agent) *mcp.Prompt {
+	args := []*mcp.PromptArgument{{
+		Name:        taskArgument,
+		Description: "What you want the agent to do",
+	}}
+	for _, name := range agent.Arguments {
+		if name == taskArgument || name == repoArgument {
+			continue
+		}
+		args = append(args, &mcp.PromptArgument{Name: name})
+	}
+
+	return &mcp.Prompt{
+		Name:        agent.Name,
+		Description: agent.Description,
+		Arguments:   args,
+	}
+}
+
+// getAgentPrompt renders an agent's instructions with the supplied arguments.
+func (s *Server) getAgentPrompt(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	_, agent, err := s.findAgent(req.Params.Arguments[repoArgument], req.Params.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.GetPromptResult{
+		Description: agent.Description,
+		Messages: []*mcp.PromptMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: renderAgentPrompt(agent, agent.Instructions, req.Params.Arguments)},
+		}},
+	}, nil
+}
+
+// renderAgentPrompt formats the agent role, its instructions, and the
+// supplied arguments as a single prompt.
+//
+// Prompt Structure:
+//
+//	You are the <agent-name>. <agent-description>
+//
+//	<instructions>
+//
+//	Task: <task>
+//	<argument>: <value>
+//	...
+func renderAgentPrompt(agent *agents.Agent, instructions string, args map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are the %s. %s", agent.Name, agent.Description)
+
+	if instructions != "" {
+		b.WriteString("\n\n")
+		b.WriteString(instructions)
+	}
+
+	var details []string
+	if task := strings.TrimSpace(args[taskArgument]); task != "" {
+		details = append(details, "Task: "+task)
+	}
+	for _, name := range agent.Arguments {
+		if name == taskArgument {
+			continue
+		}
+		if value := strings.TrimSpace(args[name]); value != "" {
+			details = append(details, fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+	if len(details) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(strings.Join(details, "\n"))
+	}
+
+	return b.String()
+}
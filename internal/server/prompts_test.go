@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+func TestServer_ListAgentPrompts(t *testing.T) {
+	srv := newTestServer(t)
+	defaultRegistry(t, srv).Get("code-reviewer").Arguments = []string{"file"}
+	srv.SyncAgents()
+	session := connectTestClient(t, srv)
+
+	res, err := session.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+
+	prompts := make(map[string]*mcp.Prompt)
+	for _, p := range res.Prompts {
+		prompts[p.Name] = p
+	}
+
+	reviewer := prompts["code-reviewer"]
+	if reviewer == nil {
+		t.Fatalf("expected code-reviewer prompt, got %v", prompts)
+	}
+	if len(reviewer.Arguments) != 2 || reviewer.Arguments[0].Name != "task" || reviewer.Arguments[1].Name != "file" {
+		t.Errorf("expected arguments [task file], got %+v", reviewer.Arguments)
+	}
+	if prompts["test-generator"] == nil {
+		t.Error("expected test-generator prompt")
+	}
+}
+
+func TestServer_GetAgentPrompt(t *testing.T) {
+	srv := newTestServer(t)
+	defaultRegistry(t, srv).Get("code-reviewer").Arguments = []string{"file"}
+	session := connectTestClient(t, srv)
+
+	res, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{
+		Name: "code-reviewer",
+		Arguments: map[string]string{
+			"task": "Review for race conditions",
+			"file": "internal/agents/types.go",
+		},
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if len(res.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(res.Messages))
+	}
+
+	text := res.Messages[0].Content.(*mcp.TextContent).Text
+	for _, expected := range []string{
+		"You are the code-reviewer. Reviews code",
+		"Task: Review for race conditions",
+		"file: internal/agents/types.go",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", expected, text)
+		}
+	}
+}
+
+func TestServer_SyncAgents_NotifiesPromptListChanged(t *testing.T) {
+	srv := newTestServer(t)
+
+	changed := make(chan struct{}, 10)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		PromptListChangedHandler: func(context.Context, *mcp.PromptListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+
+	defaultRegistry(t, srv).Add(&agents.Agent{Name: "documentation-writer", Description: "Writes docs"})
+	srv.SyncAgents()
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected prompts/list_changed notification")
+	}
+
+	res, err := session.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(res.Prompts) != 3 {
+		t.Errorf("expected 3 prompts after sync, got %d", len(res.Prompts))
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+)
+
+// ListReposInput holds the arguments of the list_repos tool.
+type ListReposInput struct{}
+
+// ListReposOutput is the result of the list_repos tool.
+type ListReposOutput struct {
+	Repos []workspace.Info `json:"repos"`
+	Count int              `json:"count"`
+}
+
+// handleListRepos lists the repositories served by this server.
+func (s *Server) handleListRepos(_ context.Context, req *mcp.CallToolRequest, _ ListReposInput) (*mcp.CallToolResult, any, error) {
+	infos := s.workspace.Infos()
+	if repo, err := s.repoFor(req.Session, ""); err == nil {
+		for i := range infos {
+			infos[i].Default = infos[i].Name == repo.Name
+		}
+	}
+	return jsonResult(ListReposOutput{
+		Repos: infos,
+		Count: len(infos),
+	})
+}
+
+// findAgent looks up an agent for the MCP features that are not scoped to a
+// repository, such as prompts. A non-empty repoName restricts the lookup to
+// that repository; otherwise the repositories are searched in order and the
+// first match wins.
+func (s *Server) findAgent(repoName, agentName string) (*workspace.Repo, *agents.Agent, error) {
+	if repoName != "" {
+		repo, err := s.workspace.Get(repoName)
+		if err != nil {
+			return nil, nil, err
+		}
+		agent, err := repo.Registry.Lookup(agentName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, agent, nil
+	}
+
+	for _, repo := range s.workspace.All() {
+		if agent := repo.Registry.Get(agentName); agent != nil {
+			return repo, agent, nil
+		}
+	}
+	return nil, nil, &agents.NotFoundError{Name: agentName}
+}
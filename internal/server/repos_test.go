@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+// newMultiRepoServer creates a server hosting the app repository (the default)
+// with the standard test agents and a search repository with one agent.
+func newMultiRepoServer(t *testing.T) *Server {
+	t.Helper()
+
+	search := agents.NewRegistry()
+	search.Add(&agents.Agent{
+		Name:        "index-tuner",
+		Description: "Tunes search indexes",
+		Keywords:    []string{"index", "search"},
+	})
+	return newTestServerWithRepos(t, Options{}, testRepo{"app", testRegistry()}, testRepo{"search", search})
+}
+
+func TestServer_ListRepos(t *testing.T) {
+	session := connectTestClient(t, newMultiRepoServer(t))
+
+	var out ListReposOutput
+	callTool(t, session, "list_repos", map[string]any{}, &out)
+
+	if out.Count != 2 || out.Repos[0].Name != "app" || !out.Repos[0].Default {
+		t.Fatalf("expected app as default repository, got %+v", out.Repos)
+	}
+	if out.Repos[1].Name != "search" || out.Repos[1].AgentCount != 1 {
+		t.Errorf("unexpected search repository: %+v", out.Repos[1])
+	}
+}
+
+func TestServer_RepoArgument(t *testing.T) {
+	session := connectTestClient(t, newMultiRepoServer(t))
+
+	tests := []struct {
+		name      string
+		repo      string
+		wantRepo  string
+		wantCount int
+	}{
+		{"default repository", "", "app", 2},
+		{"named repository", "search", "search", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out ListAgentsOutput
+			callTool(t, session, "list_agents", map[string]any{"repo": tt.repo}, &out)
+			if out.Repo != tt.wantRepo || out.Count != tt.wantCount {
+				t.Errorf("expected %d agents in %s, got %d in %s", tt.wantCount, tt.wantRepo, out.Count, out.Repo)
+			}
+		})
+	}
+
+	res := callTool(t, session, "run_agent", map[string]any{
+		"agentName": "index-tuner",
+		"prompt":    "Tune the product index",
+	}, nil)
+	if te := decodeToolError(t, res); te.Code != CodeAgentNotFound {
+		t.Errorf("expected %s for agent of another repository, got %s", CodeAgentNotFound, te.Code)
+	}
+
+	res = callTool(t, session, "list_agents", map[string]any{"repo": "billing"}, nil)
+	if !res.IsError {
+		t.Fatal("expected tool error for unknown repository")
+	}
+	if te := decodeToolError(t, res); te.Code != CodeRepoNotFound || te.Details != "available repositories: app, search" {
+		t.Errorf("unexpected error for unknown repository: %+v", te)
+	}
+}
+
+func TestServer_MultiRepoResourcesAndPrompts(t *testing.T) {
+	session := connectTestClient(t, newMultiRepoServer(t))
+	ctx := context.Background()
+
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "agent://index-tuner?repo=search"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if len(res.Contents) != 2 {
+		t.Errorf("expected 2 contents, got %d", len(res.Contents))
+	}
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "agent://index-tuner"}); err == nil {
+		t.Error("expected error reading a search agent from the default repository")
+	}
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(prompts.Prompts) != 3 {
+		t.Fatalf("expected 3 prompts across repositories, got %d", len(prompts.Prompts))
+	}
+	for _, p := range prompts.Prompts {
+		if last := p.Arguments[len(p.Arguments)-1]; last.Name != repoArgument {
+			t.Errorf("expected prompt %s to accept a repo argument, got %s", p.Name, last.Name)
+		}
+	}
+
+	got, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "index-tuner",
+		Arguments: map[string]string{"task": "Tune the product index"},
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if len(got.Messages) != 1 {
+		t.Errorf("expected 1 message, got %d", len(got.Messages))
+	}
+}
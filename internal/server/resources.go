@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// agentURIPrefix is the URI prefix of agent resources, e.g. agent://code-reviewer.
+const agentURIPrefix = "agent://"
+
+// agentURI returns the resource URI for the named agent. Agents of a
+// repository other than the default one carry the repository as a query
+// parameter, e.g. agent://code-reviewer?repo=payments.
+func agentURI(name, repo string) string {
+	if repo == "" {
+		return agentURIPrefix + name
+	}
+	return agentURIPrefix + name + "?" + url.Values{"repo": {repo}}.Encode()
+}
+
+// parseAgentURI splits an agent resource URI into the agent name and the
+// repository, which is empty for the default repository.
+func parseAgentURI(uri string) (name, repo string) {
+	name, query, _ := strings.Cut(strings.TrimPrefix(uri, agentURIPrefix), "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return name, ""
+	}
+	return name, values.Get("repo")
+}
+
+// registerResources registers the agent resource template and publishes one
+// resource per discovered agent.
+func (s *Server) registerResources() {
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "agent",
+		Title:       "Agent definition",
+		Description: "Look up an agent definition by name, optionally in a specific repository.",
+		MIMEType:    "text/markdown",
+		URITemplate: agentURIPrefix + "{+name}{?repo}",
+	}, s.readAgentResource)
+
+	s.syncAgentResources()
+}
+
+// syncAgentResources brings the published agent resources in line with the
+// registries of every repository. Adding or removing resources notifies
+// connected clients with notifications/resources/list_changed.
+func (s *Server) syncAgentResources() {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+
+	current := make(map[string]bool)
+	for i, repo := range s.workspace.All() {
+		for _, agent := range repo.Registry.All() {
+			resource := agentResource(agent, repo, i == 0)
+			current[resource.URI] = true
+			s.mcp.AddResource(resource, s.readAgentResource)
+		}
+	}
+
+	var stale []string
+	for uri := range s.agentResources {
+		if !current[uri] {
+			stale = append(stale, uri)
+		}
+	}
+	if len(stale) > 0 {
+		s.mcp.RemoveResources(stale...)
+	}
+
+	s.agentResources = current
+	s.logger.Debug("agent resources synced",
+		zap.Int("count", len(current)),
+		zap.Int("removed", len(stale)),
+	)
+}
+
+// agentResource builds the resource for an agent of repo. Agents of the
+// default repository keep their plain name and URI.
+func agentResource(agent *agents.Agent, repo *workspace.Repo, isDefault bool) *mcp.Resource {
+	if isDefault {
+		return &mcp.Resource{
+			Name:        agent.Name,
+			Description: agent.Description,
+			MIMEType:    "text/markdown",
+			URI:         agentURI(agent.Name, ""),
+		}
+	}
+	return &mcp.Resource{
+		Name:        repo.Name + "/" + agent.Name,
+		Description: agent.Description,
+		MIMEType:    "text/markdown",
+		URI:         agentURI(agent.Name, repo.Name),
+	}
+}
+
+// readAgentResource returns an agent's definition file and its parsed metadata.
+func (s *Server) readAgentResource(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	name, repoName := parseAgentURI(uri)
+
+	repo, err := s.workspace.Get(repoName)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	agent := repo.Registry.Get(name)
+	if agent == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	definition, err := agentDefinition(agent)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := json.MarshalIndent(agent, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode agent metadata: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "text/markdown", Text: definition},
+			{URI: uri, MIMEType: "application/json", Text: string(metadata)},
+		},
+	}, nil
+}
+
+// agentDefinition returns the Markdown definition of an agent: its source file
+// when it was discovered on disk, otherwise frontmatter rebuilt from metadata.
+func agentDefinition(agent *agents.Agent) (string, error) {
+	if agent.Path != "" {
+		content, err := os.ReadFile(agent.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read agent file: %w", err)
+		}
+		return string(content), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "name: %s\n", agent.Name)
+	fmt.Fprintf(&b, "description: %s\n", agent.Description)
+	fmt.Fprintf(&b, "keywords: [%s]\n", strings.Join(agent.Keywords, ", "))
+	b.WriteString("---\n")
+	return b.String(), nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+)
+
+func TestServer_ListAgentResources(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res, err := session.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+
+	uris := make(map[string]bool)
+	for _, r := range res.Resources {
+		uris[r.URI] = true
+	}
+	for _, expected := range []string{"agent://code-reviewer", "agent://test-generator"} {
+		if !uris[expected] {
+			t.Errorf("expected resource %q, got %v", expected, uris)
+		}
+	}
+
+	templates, err := session.ListResourceTemplates(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 1 || templates.ResourceTemplates[0].URITemplate != "agent://{+name}{?repo}" {
+		t.Errorf("expected agent://{+name}{?repo} template, got %+v", templates.ResourceTemplates)
+	}
+}
+
+func TestServer_ReadAgentResource(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "code-reviewer.md")
+	content := "---\nname: code-reviewer\ndescription: Reviews code\nkeywords: [quality]\n---\n\n# Code Reviewer\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newTestServer(t)
+	defaultRegistry(t, srv).Get("code-reviewer").Path = path
+	session := connectTestClient(t, srv)
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "agent://code-reviewer"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if len(res.Contents) != 2 {
+		t.Fatalf("expected 2 contents, got %d", len(res.Contents))
+	}
+	if res.Contents[0].Text != content {
+		t.Errorf("expected agent file content, got %q", res.Contents[0].Text)
+	}
+	if !strings.Contains(res.Contents[1].Text, `"name": "code-reviewer"`) {
+		t.Errorf("expected JSON metadata, got %q", res.Contents[1].Text)
+	}
+}
+
+func TestServer_ReadAgentResource_Namespaced(t *testing.T) {
+	registry := testRegistry()
+	registry.Add(&agents.Agent{Name: "security/secret-scanner", Namespace: "security", Description: "Finds secrets"})
+	session := connectTestClient(t, newTestServerWithRegistry(t, registry))
+
+	// The published resource, and the template with an explicit repository.
+	for _, uri := range []string{"agent://security/secret-scanner", "agent://security/secret-scanner?repo=app"} {
+		res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		if !strings.Contains(res.Contents[1].Text, `"namespace": "security"`) {
+			t.Errorf("expected namespaced metadata for %s, got %q", uri, res.Contents[1].Text)
+		}
+	}
+}
+
+func TestServer_ReadAgentResource_NotFound(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	_, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "agent://nonexistent"})
+	if err == nil {
+		t.Error("expected error for unknown agent resource")
+	}
+}
+
+func TestServer_SyncAgents_NotifiesListChanged(t *testing.T) {
+	srv := newTestServer(t)
+
+	changed := make(chan struct{}, 10)
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+
+	defaultRegistry(t, srv).Add(&agents.Agent{Name: "documentation-writer", Description: "Writes docs"})
+	srv.SyncAgents()
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected resources/list_changed notification")
+	}
+
+	res, err := session.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(res.Resources) != 3 {
+		t.Errorf("expected 3 resources after sync, got %d", len(res.Resources))
+	}
+}
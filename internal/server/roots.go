@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// rootsTimeout bounds how long the server waits for a client to answer
+// roots/list.
+const rootsTimeout = 10 * time.Second
+
+// RootsMode selects how client roots combine with the configured repositories.
+type RootsMode string
+
+const (
+	// RootsMerge serves client roots in addition to the configured
+	// repositories. Each session's first root is its default repository.
+	RootsMerge RootsMode = "merge"
+	// RootsReplace serves only the client roots while a client provides
+	// any, and the configured repositories otherwise.
+	RootsReplace RootsMode = "replace"
+	// RootsOff ignores client roots.
+	RootsOff RootsMode = "off"
+)
+
+// ParseRootsMode validates a roots mode name.
+func ParseRootsMode(name string) (RootsMode, bool) {
+	switch m := RootsMode(name); m {
+	case RootsMerge, RootsReplace, RootsOff:
+		return m, true
+	}
+	return "", false
+}
+
+// sessionRoots holds the root directories reported by one client session.
+type sessionRoots struct {
+	session *mcp.ServerSession
+	paths   []string
+}
+
+// onInitialized logs a newly initialized session and, unless client roots are
+// ignored, serves the client's roots for as long as the session lasts.
+func (s *Server) onInitialized(_ context.Context, req *mcp.InitializedRequest) {
+	s.logSession(req.Session)
+	if s.rootsMode == RootsOff {
+		return
+	}
+
+	// Listing roots is a request to the client, which must not block the
+	// handler delivering the client's messages.
+	go func() {
+		s.refreshRoots(req.Session, false)
+		_ = req.Session.Wait()
+		s.forgetRoots(req.Session)
+	}()
+}
+
+// onRootsListChanged rereads a client's roots and rediscovers their agents.
+func (s *Server) onRootsListChanged(_ context.Context, req *mcp.RootsListChangedRequest) {
+	if s.rootsMode == RootsOff {
+		return
+	}
+	go s.refreshRoots(req.Session, true)
+}
+
+// refreshRoots lists the roots of session and brings the workspace in line
+// with them. With reload, roots the session already had are rediscovered
+// too, picking up agents added or removed since they were first served.
+func (s *Server) refreshRoots(session *mcp.ServerSession, reload bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), rootsTimeout)
+	defer cancel()
+
+	res, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		s.logger.Debug("client roots unavailable",
+			zap.String("session", session.ID()),
+			zap.Error(err),
+		)
+		return
+	}
+	paths := rootPaths(res.Roots, s.logger)
+
+	s.rootsMu.Lock()
+	defer s.rootsMu.Unlock()
+
+	var previous []string
+	i := slices.IndexFunc(s.clientRoots, func(r sessionRoots) bool { return r.session == session })
+	if i >= 0 {
+		previous = s.clientRoots[i].paths
+		s.clientRoots[i].paths = paths
+	} else {
+		s.clientRoots = append(s.clientRoots, sessionRoots{session: session, paths: paths})
+	}
+
+	s.logger.Info("client roots updated",
+		zap.String("session", session.ID()),
+		zap.Strings("roots", paths),
+	)
+
+	changed := s.workspace.Sync(s.desiredRoots())
+	if reload {
+		for _, path := range paths {
+			if !slices.Contains(previous, path) {
+				continue
+			}
+			if err := s.workspace.Reload(path); err != nil {
+				s.logger.Warn("failed to rediscover agents", zap.String("root", path), zap.Error(err))
+				continue
+			}
+			changed = true
+		}
+	}
+	if changed {
+		s.SyncAgents()
+	}
+}
+
+// forgetRoots stops serving the roots of a session that has ended.
+func (s *Server) forgetRoots(session *mcp.ServerSession) {
+	s.rootsMu.Lock()
+	defer s.rootsMu.Unlock()
+
+	s.clientRoots = slices.DeleteFunc(s.clientRoots, func(r sessionRoots) bool { return r.session == session })
+	if s.workspace.Sync(s.desiredRoots()) {
+		s.SyncAgents()
+	}
+}
+
+// desiredRoots returns the repository roots to serve: the roots of every
+// session in connection order, followed by the repositories added to the
+// workspace unless they are replaced. The caller must hold s.rootsMu.
+func (s *Server) desiredRoots() []string {
+	var roots []string
+	for _, r := range s.clientRoots {
+		for _, path := range r.paths {
+			if !slices.Contains(roots, path) {
+				roots = append(roots, path)
+			}
+		}
+	}
+	if len(roots) > 0 && s.rootsMode == RootsReplace {
+		return roots
+	}
+	for _, path := range s.workspace.ConfiguredRoots() {
+		if !slices.Contains(roots, path) {
+			roots = append(roots, path)
+		}
+	}
+	return roots
+}
+
+// repoFor returns the named repository, or the default repository of
+// session when name is empty: the repository of the session's first served
+// root, so clients sharing a server over HTTP each default to their own
+// folder. Sessions without served roots, and all sessions when client roots
+// are ignored, use the workspace's default repository.
+func (s *Server) repoFor(session *mcp.ServerSession, name string) (*workspace.Repo, error) {
+	if name == "" && session != nil {
+		repos := s.workspace.All()
+		for _, root := range s.sessionRoots(session) {
+			for _, repo := range repos {
+				if repo.Root == root {
+					return repo, nil
+				}
+			}
+		}
+	}
+	return s.workspace.Get(name)
+}
+
+// sessionRoots returns the roots last reported by session.
+func (s *Server) sessionRoots(session *mcp.ServerSession) []string {
+	s.rootsMu.Lock()
+	defer s.rootsMu.Unlock()
+
+	for _, r := range s.clientRoots {
+		if r.session == session {
+			return slices.Clone(r.paths)
+		}
+	}
+	return nil
+}
+
+// rootPaths converts client roots to absolute directory paths. Roots that
+// are not absolute file URIs are skipped.
+func rootPaths(roots []*mcp.Root, logger *zap.Logger) []string {
+	var paths []string
+	for _, root := range roots {
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			logger.Warn("ignoring unsupported client root", zap.String("uri", root.URI))
+			continue
+		}
+		path := filepath.Clean(filepath.FromSlash(u.Path))
+		if !filepath.IsAbs(path) {
+			logger.Warn("ignoring relative client root", zap.String("uri", root.URI))
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// writeAgentRepo creates a repository directory named name defining agent and
+// returns its path.
+func writeAgentRepo(t *testing.T, name, agent string) string {
+	t.Helper()
+
+	root := filepath.Join(t.TempDir(), name)
+	dir := filepath.Join(root, ".github", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + agent + "\ndescription: Test agent\nkeywords: [test]\n---\n"
+	if err := os.WriteFile(filepath.Join(dir, agent+".md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// fileRoot returns the client root for a directory.
+func fileRoot(path string) *mcp.Root {
+	return &mcp.Root{URI: "file://" + filepath.ToSlash(path)}
+}
+
+// waitRepos polls list_repos until the served repositories are want, in order.
+func waitRepos(t *testing.T, session *mcp.ClientSession, want ...string) {
+	t.Helper()
+
+	var got []string
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var out ListReposOutput
+		callTool(t, session, "list_repos", map[string]any{}, &out)
+		got = got[:0]
+		for _, repo := range out.Repos {
+			got = append(got, repo.Name)
+		}
+		if slices.Equal(got, want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected repositories %v, got %v", want, got)
+}
+
+func TestServer_ClientRoots(t *testing.T) {
+	payments := writeAgentRepo(t, "payments", "code-reviewer")
+	search := writeAgentRepo(t, "search", "index-tuner")
+
+	tests := []struct {
+		name       string
+		mode       RootsMode
+		withRoots  []string
+		afterRoots []string
+	}{
+		{"merge", RootsMerge, []string{"payments", "app"}, []string{"payments", "search", "app"}},
+		{"replace", RootsReplace, []string{"payments"}, []string{"payments", "search"}},
+		{"off", RootsOff, []string{"app"}, []string{"app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServerWithRepos(t, Options{Roots: tt.mode}, testRepo{"app", testRegistry()})
+
+			client := mcp.NewClient(&mcp.Implementation{Name: "editor", Version: "test"}, nil)
+			client.AddRoots(fileRoot(payments))
+			session := connectClient(t, srv, client)
+			waitRepos(t, session, tt.withRoots...)
+
+			client.AddRoots(fileRoot(search))
+			waitRepos(t, session, tt.afterRoots...)
+
+			client.RemoveRoots(fileRoot(payments).URI, fileRoot(search).URI)
+			waitRepos(t, session, "app")
+		})
+	}
+}
+
+func TestServer_ClientRoots_KeepsAddedRepos(t *testing.T) {
+	payments := writeAgentRepo(t, "payments", "code-reviewer")
+	search := writeAgentRepo(t, "search", "index-tuner")
+
+	srv := newTestServerWithRepos(t, Options{}, testRepo{"app", testRegistry()})
+	if _, err := srv.workspace.Add(search); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repositories added after the server was created are configured too.
+	client := mcp.NewClient(&mcp.Implementation{Name: "editor", Version: "test"}, nil)
+	client.AddRoots(fileRoot(payments))
+	session := connectClient(t, srv, client)
+	waitRepos(t, session, "payments", "app", "search")
+}
+
+func TestServer_ClientRoots_SessionDefault(t *testing.T) {
+	payments := writeAgentRepo(t, "payments", "code-reviewer")
+	search := writeAgentRepo(t, "search", "index-tuner")
+	content := "---\nname: code-reviewer\ndescription: Reviews search code\nkeywords: [review]\n---\n"
+	if err := os.WriteFile(filepath.Join(search, ".github", "agents", "code-reviewer.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServerWithRepos(t, Options{}, testRepo{"app", testRegistry()})
+
+	first := mcp.NewClient(&mcp.Implementation{Name: "editor", Version: "test"}, nil)
+	first.AddRoots(fileRoot(payments))
+	firstSession := connectClient(t, srv, first)
+	waitRepos(t, firstSession, "payments", "app")
+
+	second := mcp.NewClient(&mcp.Implementation{Name: "editor", Version: "test"}, nil)
+	second.AddRoots(fileRoot(search))
+	secondSession := connectClient(t, srv, second)
+	waitRepos(t, secondSession, "payments", "search", "app")
+
+	// Each session defaults to the repository of its own roots.
+	for _, tt := range []struct {
+		session *mcp.ClientSession
+		repo    string
+	}{
+		{firstSession, "payments"},
+		{secondSession, "search"},
+	} {
+		var agents ListAgentsOutput
+		callTool(t, tt.session, "list_agents", map[string]any{}, &agents)
+		if agents.Repo != tt.repo {
+			t.Errorf("list_agents repo = %q, want %q", agents.Repo, tt.repo)
+		}
+
+		var repos ListReposOutput
+		callTool(t, tt.session, "list_repos", map[string]any{}, &repos)
+		for _, repo := range repos.Repos {
+			if repo.Default != (repo.Name == tt.repo) {
+				t.Errorf("list_repos default of %q = %v, want default %q", repo.Name, repo.Default, tt.repo)
+			}
+		}
+	}
+
+	// Agent resources and prompts are listed alike to every session, so each
+	// session reads and renders the agents it lists.
+	ctx := context.Background()
+	for _, session := range []*mcp.ClientSession{firstSession, secondSession} {
+		resources, err := session.ListResources(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		for _, resource := range resources.Resources {
+			read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: resource.URI})
+			if err != nil {
+				t.Errorf("ReadResource(%s) failed: %v", resource.URI, err)
+				continue
+			}
+			if text := read.Contents[0].Text; !strings.Contains(text, "description: "+resource.Description) {
+				t.Errorf("resource %s read a different agent than listed:\n%s", resource.URI, text)
+			}
+		}
+
+		prompts, err := session.ListPrompts(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListPrompts failed: %v", err)
+		}
+		for _, listed := range prompts.Prompts {
+			got, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: listed.Name})
+			if err != nil {
+				t.Errorf("GetPrompt(%s) failed: %v", listed.Name, err)
+				continue
+			}
+			if got.Description != listed.Description {
+				t.Errorf("prompt %s description = %q, listed as %q", listed.Name, got.Description, listed.Description)
+			}
+		}
+	}
+}
+
+func TestServer_ClientRoots_RediscoverOnChange(t *testing.T) {
+	payments := writeAgentRepo(t, "payments", "code-reviewer")
+	srv := newTestServerWithRepos(t, Options{}, testRepo{"app", testRegistry()})
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "editor", Version: "test"}, nil)
+	client.AddRoots(fileRoot(payments))
+	session := connectClient(t, srv, client)
+	waitRepos(t, session, "payments", "app")
+
+	// A new agent file is picked up on the next roots/list_changed.
+	content := "---\nname: doc-writer\ndescription: Writes docs\nkeywords: [docs]\n---\n"
+	if err := os.WriteFile(filepath.Join(payments, ".github", "agents", "doc-writer.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client.AddRoots(fileRoot(payments))
+
+	// The new agent is published once the repository has been rediscovered.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		prompts, err := session.ListPrompts(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListPrompts failed: %v", err)
+		}
+		if len(prompts.Prompts) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 prompts after rediscovery, got %d", len(prompts.Prompts))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var out ListAgentsOutput
+	callTool(t, session, "list_agents", map[string]any{"repo": "payments"}, &out)
+	if out.Count != 2 {
+		t.Errorf("expected 2 agents in payments after rediscovery, got %d", out.Count)
+	}
+}
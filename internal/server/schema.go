@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/diagnostics"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+)
+
+// schemaOptions adjusts schema inference for types whose JSON encoding
+// differs from their Go shape.
+var schemaOptions = &jsonschema.ForOptions{
+	TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+		// Agent output is arbitrary JSON produced by the Copilot CLI.
+		reflect.TypeFor[json.RawMessage](): {},
+	},
+}
+
+// Tools returns the definitions of every tool the server registers, with
+// input and output JSON Schemas derived from the Go types the handlers
+// decode and return.
+func Tools() []*mcp.Tool {
+	return []*mcp.Tool{
+		newTool[RunWithOrchestratorInput, orchestrator.ContextState](
+			"run_with_orchestrator",
+			"Evaluate a prompt, select the best agents, and execute them as a chain. Pass agents to run an explicit chain instead.",
+		),
+		newTool[ListAgentsInput, ListAgentsOutput](
+			"list_agents",
+			"List all available agents and their capabilities.",
+		),
+		newTool[RunAgentInput, cli.InvocationResult](
+			"run_agent",
+			"Run a specific agent directly, bypassing the orchestrator.",
+		),
+		newTool[EvaluatePromptInput, EvaluatePromptOutput](
+			"evaluate_prompt",
+			"Evaluate a prompt for clarity and preview the agent chain run_with_orchestrator would run.",
+		),
+		newTool[RunWithOrchestratorInput, jobs.Job](
+			"start_orchestration",
+			"Start run_with_orchestrator as a background job and return its job ID immediately. Poll with get_job_status.",
+		),
+		newTool[JobInput, jobs.Job](
+			"get_job_status",
+			"Get the status and latest progress of an orchestration job.",
+		),
+		newTool[JobInput, JobResultOutput](
+			"get_job_result",
+			"Get the orchestration result of a finished job.",
+		),
+		newTool[JobInput, jobs.Job](
+			"cancel_job",
+			"Cancel a queued or running orchestration job.",
+		),
+		newTool[ListJobsInput, ListJobsOutput](
+			"list_jobs",
+			"List orchestration jobs, newest first.",
+		),
+		newTool[ListReposInput, ListReposOutput](
+			"list_repos",
+			"List the repositories this server hosts. Pass a repository name as repo to the other tools.",
+		),
+		newTool[DiagnoseInput, diagnostics.Report](
+			"diagnose",
+			"Check the Copilot CLI version and auth state, the configuration, and agent discovery, returning a pass/warn/fail report.",
+		),
+		newTool[LintAgentsInput, LintAgentsOutput](
+			"lint_agents",
+			"Check a repository's agent files for invalid frontmatter, missing or duplicate names, invalid extends, empty descriptions, unknown keys, keywords no prompt produces and overlapping keyword sets.",
+		),
+	}
+}
+
+// newTool builds a tool definition whose schemas are inferred from In and Out.
+func newTool[In, Out any](name, description string) *mcp.Tool {
+	return &mcp.Tool{
+		Name:         name,
+		Description:  description,
+		InputSchema:  mustSchema[In](),
+		OutputSchema: mustSchema[Out](),
+	}
+}
+
+// mustSchema infers the JSON Schema for T. The tool types are fixed at
+// compile time, so a failure is a programming error and panics, as
+// mcp.AddTool does.
+func mustSchema[T any]() *jsonschema.Schema {
+	s, err := jsonschema.For[T](schemaOptions)
+	if err != nil {
+		panic(fmt.Sprintf("schema for %s: %v", reflect.TypeFor[T](), err))
+	}
+	allowNullArrays(s)
+	return s
+}
+
+// allowNullArrays permits null wherever s (recursively) expects an array,
+// because nil Go slices encode as JSON null.
+func allowNullArrays(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	if s.Type == "array" {
+		s.Type = ""
+		s.Types = []string{"null", "array"}
+	} else if slices.Contains(s.Types, "array") && !slices.Contains(s.Types, "null") {
+		s.Types = append([]string{"null"}, s.Types...)
+	}
+
+	for _, prop := range s.Properties {
+		allowNullArrays(prop)
+	}
+	allowNullArrays(s.Items)
+	allowNullArrays(s.AdditionalProperties)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTools_Schemas(t *testing.T) {
+	for _, tool := range Tools() {
+		t.Run(tool.Name, func(t *testing.T) {
+			for kind, schema := range map[string]any{"input": tool.InputSchema, "output": tool.OutputSchema} {
+				s, ok := schema.(*jsonschema.Schema)
+				if !ok || s.Type != "object" {
+					t.Fatalf("expected %s schema of type object, got %#v", kind, schema)
+				}
+				if _, err := s.Resolve(nil); err != nil {
+					t.Errorf("%s schema does not resolve: %v", kind, err)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_StructuredContentMatchesOutputSchema(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	listed, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	schemas := make(map[string]*jsonschema.Resolved)
+	for _, tool := range listed.Tools {
+		if tool.OutputSchema == nil {
+			t.Fatalf("tool %s does not advertise an outputSchema", tool.Name)
+		}
+		data, _ := json.Marshal(tool.OutputSchema)
+		var s jsonschema.Schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("tool %s: invalid outputSchema: %v", tool.Name, err)
+		}
+		resolved, err := s.Resolve(nil)
+		if err != nil {
+			t.Fatalf("tool %s: outputSchema does not resolve: %v", tool.Name, err)
+		}
+		schemas[tool.Name] = resolved
+	}
+
+	tests := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"list_agents", map[string]any{}},
+		{"evaluate_prompt", map[string]any{"prompt": "Review auth.go for security issues"}},
+		{"run_with_orchestrator", map[string]any{"prompt": "Review auth.go", "agents": []string{"code-reviewer"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			res := callTool(t, session, tt.tool, tt.args, nil)
+			if res.IsError {
+				t.Fatalf("unexpected tool error: %+v", res.Content)
+			}
+			if res.StructuredContent == nil {
+				t.Fatal("expected structuredContent")
+			}
+
+			data, _ := json.Marshal(res.StructuredContent)
+			var instance map[string]any
+			if err := json.Unmarshal(data, &instance); err != nil {
+				t.Fatalf("structuredContent is not an object: %v", err)
+			}
+			if err := schemas[tt.tool].Validate(instance); err != nil {
+				t.Errorf("structuredContent does not match outputSchema: %v", err)
+			}
+
+			text := res.Content[0].(*mcp.TextContent).Text
+			var fromText map[string]any
+			if err := json.Unmarshal([]byte(text), &fromText); err != nil {
+				t.Errorf("text content is not JSON: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/config"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// Info holds build information reported to MCP clients.
+type Info struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+	Commit    string `json:"commit"`
+}
+
+// Options configures a Server.
+type Options struct {
+	// Info is the build information reported to clients.
+	Info Info
+
+	// Backend selects how agents are invoked (default BackendCLI).
+	Backend Backend
+
+	// Roots selects how client roots combine with the repositories the
+	// workspace was created with (default RootsMerge).
+	Roots RootsMode
+
+	// Config is the resolved configuration reported by the diagnose tool.
+	Config *config.Config
+}
+
+// Server wraps an MCP server exposing the orchestrator tools.
+type Server struct {
+	mcp       *mcp.Server
+	workspace *workspace.Workspace
+	invoker   *cli.Invoker
+	jobs      *jobs.Manager
+	info      Info
+	backend   Backend
+	config    *config.Config
+	logger    *zap.Logger
+
+	resourcesMu    sync.Mutex
+	agentResources map[string]bool // URIs of published agent resources
+
+	promptsMu    sync.Mutex
+	agentPrompts map[string]bool // Names of published agent prompts
+
+	rootsMode   RootsMode
+	rootsMu     sync.Mutex
+	clientRoots []sessionRoots // Roots reported by each session, in connection order
+}
+
+// New creates a new MCP server with all orchestrator tools registered,
+// serving the repositories in ws. Asynchronous orchestration jobs are run by
+// jobManager.
+func New(ws *workspace.Workspace, invoker *cli.Invoker, jobManager *jobs.Manager, opts Options, logger *zap.Logger) *Server {
+	if opts.Backend == "" {
+		opts.Backend = BackendCLI
+	}
+	if opts.Roots == "" {
+		opts.Roots = RootsMerge
+	}
+
+	s := &Server{
+		workspace: ws,
+		invoker:   invoker,
+		jobs:      jobManager,
+		info:      opts.Info,
+		backend:   opts.Backend,
+		config:    opts.Config,
+		logger:    logger,
+		rootsMode: opts.Roots,
+	}
+
+	s.mcp = mcp.NewServer(&mcp.Implementation{
+		Name:    "copilot-os",
+		Title:   "CopilotOS",
+		Version: opts.Info.Version,
+	}, &mcp.ServerOptions{
+		InitializedHandler:      s.onInitialized,
+		RootsListChangedHandler: s.onRootsListChanged,
+	})
+
+	s.registerTools()
+	s.registerResources()
+	s.syncAgentPrompts()
+	return s
+}
+
+// SyncAgents republishes the agent-backed MCP features after a registry or
+// the set of repositories has changed, notifying connected clients of the
+// updated lists.
+func (s *Server) SyncAgents() {
+	s.syncAgentResources()
+	s.syncAgentPrompts()
+}
+
+// MCP returns the underlying MCP server.
+func (s *Server) MCP() *mcp.Server {
+	return s.mcp
+}
+
+// Run serves a single MCP session over stdin/stdout until the client
+// disconnects or ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	s.logger.Info("MCP server started",
+		zap.String("transport", "stdio"),
+		zap.String("version", s.info.Version),
+	)
+	return s.mcp.Run(ctx, &mcp.StdioTransport{})
+}
+
+// HTTPHandler returns an http.Handler serving the MCP streamable HTTP
+// transport. Each client receives its own session; idle sessions are closed
+// after sessionTimeout (zero disables the timeout).
+func (s *Server) HTTPHandler(sessionTimeout time.Duration) http.Handler {
+	return mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return s.mcp
+	}, &mcp.StreamableHTTPOptions{
+		SessionTimeout: sessionTimeout,
+	})
+}
+
+// RunHTTP serves MCP sessions over streamable HTTP on addr until ctx is
+// cancelled. The MCP endpoint is mounted at /mcp.
+func (s *Server) RunHTTP(ctx context.Context, addr string, sessionTimeout time.Duration) error {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s.HTTPHandler(sessionTimeout))
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	s.logger.Info("MCP server started",
+		zap.String("transport", "http"),
+		zap.String("listen", addr),
+		zap.String("endpoint", "/mcp"),
+		zap.String("version", s.info.Version),
+	)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
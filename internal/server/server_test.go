@@ -0,0 +1,353 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/cli"
+	"github.com/rayprogramming/copilot-os/internal/jobs"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/workspace"
+	"go.uber.org/zap"
+)
+
+// connectTestClient starts srv on an in-memory transport and returns a connected client session.
+func connectTestClient(t *testing.T, srv *Server) *mcp.ClientSession {
+	t.Helper()
+	return connectTestClientWithOptions(t, srv, nil)
+}
+
+// connectTestClientWithOptions is like connectTestClient but configures the client with opts.
+func connectTestClientWithOptions(t *testing.T, srv *Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	return connectClient(t, srv, mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, opts))
+}
+
+// connectClient connects client to srv on an in-memory transport.
+func connectClient(t *testing.T, srv *Server, client *mcp.Client) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := srv.MCP().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	return session
+}
+
+// newTestServer creates a server backed by a small in-memory registry.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServerWithRegistry(t, testRegistry())
+}
+
+// testRegistry returns a registry with the code-reviewer and test-generator agents.
+func testRegistry() *agents.Registry {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{
+		Name:        "code-reviewer",
+		Description: "Reviews code",
+		Keywords:    []string{"code-review", "quality"},
+	})
+	registry.Add(&agents.Agent{
+		Name:        "test-generator",
+		Description: "Generates tests",
+		Keywords:    []string{"test-generator", "testing"},
+	})
+	return registry
+}
+
+// newTestServerWithRegistry creates a server for registry whose invoker cannot
+// find the copilot binary, so every agent fails fast without spawning a process.
+func newTestServerWithRegistry(t *testing.T, registry *agents.Registry) *Server {
+	t.Helper()
+	return newTestServerWithOptions(t, registry, Options{})
+}
+
+// newTestServerWithOptions is like newTestServerWithRegistry but configures
+// the server with opts.
+func newTestServerWithOptions(t *testing.T, registry *agents.Registry, opts Options) *Server {
+	t.Helper()
+	return newTestServerWithRepos(t, opts, testRepo{"app", registry})
+}
+
+// testRepo is a repository served by a test server.
+type testRepo struct {
+	name     string
+	registry *agents.Registry
+}
+
+// newTestServerWithRepos creates a server hosting repos, the first being the
+// default repository.
+func newTestServerWithRepos(t *testing.T, opts Options, repos ...testRepo) *Server {
+	t.Helper()
+	t.Setenv("PATH", t.TempDir())
+
+	logger := zap.NewNop()
+	invoker := cli.NewInvoker(time.Second, logger)
+	ws := workspace.New(invoker, logger)
+	for _, repo := range repos {
+		if _, err := ws.AddRegistry(repo.name, "/src/"+repo.name, repo.registry); err != nil {
+			t.Fatalf("failed to add repository %s: %v", repo.name, err)
+		}
+	}
+	jobManager := jobs.NewManager(ws, jobs.Options{}, logger)
+	t.Cleanup(jobManager.Close)
+
+	opts.Info = Info{Version: "test"}
+	return New(ws, invoker, jobManager, opts, logger)
+}
+
+// defaultRegistry returns the registry of srv's default repository.
+func defaultRegistry(t *testing.T, srv *Server) *agents.Registry {
+	t.Helper()
+	repo, err := srv.workspace.Get("")
+	if err != nil {
+		t.Fatalf("no default repository: %v", err)
+	}
+	return repo.Registry
+}
+
+// callTool calls a tool and decodes its JSON text content into out.
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args any, out any) *mcp.CallToolResult {
+	t.Helper()
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	if res.IsError || out == nil {
+		return res
+	}
+	if len(res.Content) == 0 {
+		t.Fatalf("CallTool(%s) returned no content", name)
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("CallTool(%s) returned %T, want *mcp.TextContent", name, res.Content[0])
+	}
+	if err := json.Unmarshal([]byte(text.Text), out); err != nil {
+		t.Fatalf("failed to decode %s result: %v", name, err)
+	}
+	return res
+}
+
+func TestServer_ListTools(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	expected := []string{
+		"run_with_orchestrator", "list_agents", "run_agent", "evaluate_prompt",
+		"start_orchestration", "get_job_status", "get_job_result", "cancel_job", "list_jobs",
+		"list_repos", "diagnose", "lint_agents",
+	}
+	registered := make(map[string]bool)
+	for _, tool := range res.Tools {
+		registered[tool.Name] = true
+	}
+	for _, name := range expected {
+		if !registered[name] {
+			t.Errorf("expected tool %q to be registered", name)
+		}
+	}
+}
+
+func TestServer_ListAgents(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	var out ListAgentsOutput
+	callTool(t, session, "list_agents", map[string]any{}, &out)
+
+	if out.Count != 2 {
+		t.Errorf("expected 2 agents, got %d", out.Count)
+	}
+	if len(out.Agents) != 2 || out.Agents[0].Name != "code-reviewer" {
+		t.Errorf("expected code-reviewer first, got %+v", out.Agents)
+	}
+}
+
+func TestServer_ListAgents_Namespace(t *testing.T) {
+	registry := testRegistry()
+	registry.Add(&agents.Agent{Name: "security/secret-scanner", Namespace: "security"})
+	session := connectTestClient(t, newTestServerWithRegistry(t, registry))
+
+	var out ListAgentsOutput
+	callTool(t, session, "list_agents", map[string]any{"namespace": "security"}, &out)
+
+	if out.Count != 1 || out.Agents[0].Name != "security/secret-scanner" {
+		t.Errorf("expected only security/secret-scanner, got %+v", out.Agents)
+	}
+}
+
+func TestServer_EvaluatePrompt(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	var out EvaluatePromptOutput
+	callTool(t, session, "evaluate_prompt", map[string]any{
+		"prompt": "Review internal/agents/types.go for code quality",
+	}, &out)
+
+	if !out.Evaluation.IsClear {
+		t.Errorf("expected prompt to be clear, feedback: %s", out.Evaluation.Feedback)
+	}
+	if len(out.SelectedAgents) == 0 || out.SelectedAgents[0] != "code-reviewer" {
+		t.Errorf("expected code-reviewer to be selected, got %v", out.SelectedAgents)
+	}
+}
+
+func TestServer_EvaluatePrompt_PreviewsChain(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Keywords: []string{"code-review", "quality"}, Requires: []string{"linter"}})
+	registry.Add(&agents.Agent{Name: "style-checker", Keywords: []string{"quality"}})
+	registry.Add(&agents.Agent{Name: "doc-checker", Keywords: []string{"quality"}})
+	registry.Add(&agents.Agent{Name: "linter"})
+	session := connectTestClient(t, newTestServerWithRegistry(t, registry))
+	const userPrompt = "Review internal/agents/types.go for code quality"
+
+	var out EvaluatePromptOutput
+	callTool(t, session, "evaluate_prompt", map[string]any{"prompt": userPrompt}, &out)
+
+	// At most two agents are selected, and the agents they require are added
+	// ahead of them.
+	if len(out.SelectedAgents) != 3 || out.SelectedAgents[0] != "linter" || !slices.Contains(out.SelectedAgents, "code-reviewer") {
+		t.Errorf("expected linter, code-reviewer and one more agent, got %v", out.SelectedAgents)
+	}
+
+	var state orchestrator.ContextState
+	callTool(t, session, "run_with_orchestrator", map[string]any{"prompt": userPrompt}, &state)
+	if !slices.Equal(out.SelectedAgents, state.SelectedAgents) {
+		t.Errorf("expected the chain run_with_orchestrator runs, %v, got %v", state.SelectedAgents, out.SelectedAgents)
+	}
+}
+
+func TestServer_EvaluatePrompt_EmptyPrompt(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res := callTool(t, session, "evaluate_prompt", map[string]any{"prompt": "  "}, nil)
+	if !res.IsError {
+		t.Fatal("expected tool error for empty prompt")
+	}
+	if te := decodeToolError(t, res); te.Code != CodeInvalidPrompt {
+		t.Errorf("expected code %s, got %s", CodeInvalidPrompt, te.Code)
+	}
+}
+
+func TestServer_RunAgent_NotFound(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res := callTool(t, session, "run_agent", map[string]any{
+		"agentName": "nonexistent",
+		"prompt":    "Review auth.go",
+	}, nil)
+
+	if !res.IsError {
+		t.Fatal("expected tool error for unknown agent")
+	}
+	if te := decodeToolError(t, res); te.Code != CodeAgentNotFound {
+		t.Errorf("expected code %s, got %s", CodeAgentNotFound, te.Code)
+	}
+}
+
+func TestServer_RunWithOrchestrator_EmptyPrompt(t *testing.T) {
+	session := connectTestClient(t, newTestServer(t))
+
+	res := callTool(t, session, "run_with_orchestrator", map[string]any{"prompt": "  "}, nil)
+
+	if !res.IsError {
+		t.Fatal("expected tool error for empty prompt")
+	}
+	if te := decodeToolError(t, res); te.Code != CodeInvalidPrompt {
+		t.Errorf("expected code %s, got %s", CodeInvalidPrompt, te.Code)
+	}
+}
+
+func TestServer_HTTPHandler_MultipleSessions(t *testing.T) {
+	srv := newTestServer(t)
+	httpServer := httptest.NewServer(srv.HTTPHandler(0))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	var sessions []*mcp.ClientSession
+	for _, name := range []string{"client-a", "client-b"} {
+		client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "test"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+		if err != nil {
+			t.Fatalf("%s connect failed: %v", name, err)
+		}
+		defer session.Close()
+		sessions = append(sessions, session)
+	}
+
+	if sessions[0].ID() == "" || sessions[0].ID() == sessions[1].ID() {
+		t.Errorf("expected distinct session IDs, got %q and %q", sessions[0].ID(), sessions[1].ID())
+	}
+
+	for _, session := range sessions {
+		var out ListAgentsOutput
+		callTool(t, session, "list_agents", map[string]any{}, &out)
+		if out.Count != 2 {
+			t.Errorf("session %s: expected 2 agents, got %d", session.ID(), out.Count)
+		}
+	}
+}
+
+func TestServer_RunWithOrchestrator_Progress(t *testing.T) {
+	registry := agents.NewRegistry()
+	registry.Add(&agents.Agent{Name: "code-reviewer", Keywords: []string{"code-review"}})
+	srv := newTestServerWithRegistry(t, registry)
+
+	var mu sync.Mutex
+	var messages []string
+	session := connectTestClientWithOptions(t, srv, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			messages = append(messages, req.Params.Message)
+			mu.Unlock()
+		},
+	})
+
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "run-1"},
+		Name:      "run_with_orchestrator",
+		Arguments: map[string]any{"prompt": "Review auth.go", "agents": []string{"code-reviewer"}},
+	}
+	if _, err := session.CallTool(context.Background(), params); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	// evaluation, selection, agent start, agent finish, synthesis
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(messages)
+		mu.Unlock()
+		if n == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 5 progress notifications, got %d: %v", n, messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// logSession logs a session once the client has completed initialization,
+// and again when the session ends.
+//
+// With the stdio transport there is exactly one session; with the streamable
+// HTTP transport each client gets its own session, identified by the
+// Mcp-Session-Id header.
+func (s *Server) logSession(ss *mcp.ServerSession) {
+	var clientName, clientVersion string
+	if params := ss.InitializeParams(); params != nil && params.ClientInfo != nil {
+		clientName = params.ClientInfo.Name
+		clientVersion = params.ClientInfo.Version
+	}
+	s.logger.Info("client session started",
+		zap.String("session", ss.ID()),
+		zap.String("client", clientName),
+		zap.String("client_version", clientVersion),
+	)
+
+	go func() {
+		_ = ss.Wait()
+		s.logger.Info("client session ended", zap.String("session", ss.ID()))
+	}()
+}
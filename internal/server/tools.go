@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/prompt"
+	"go.uber.org/zap"
+)
+
+// RunWithOrchestratorInput holds the arguments of the run_with_orchestrator tool.
+type RunWithOrchestratorInput struct {
+	Prompt string   `json:"prompt" jsonschema:"the user's request to orchestrate"`
+	Agents []string `json:"agents,omitempty" jsonschema:"optional explicit agent chain, executed in the given order"`
+	Repo   string   `json:"repo,omitempty" jsonschema:"repository to run in, as listed by list_repos; defaults to the first repository"`
+}
+
+// ListAgentsInput holds the arguments of the list_agents tool.
+type ListAgentsInput struct {
+	Repo      string `json:"repo,omitempty" jsonschema:"repository whose agents to list; defaults to the first repository"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only list agents of this namespace and the namespaces nested in it, e.g. security"`
+}
+
+// RunAgentInput holds the arguments of the run_agent tool.
+type RunAgentInput struct {
+	AgentName string `json:"agentName" jsonschema:"name of the agent to run"`
+	Prompt    string `json:"prompt" jsonschema:"the prompt to send to the agent"`
+	Repo      string `json:"repo,omitempty" jsonschema:"repository the agent belongs to; defaults to the first repository"`
+}
+
+// EvaluatePromptInput holds the arguments of the evaluate_prompt tool.
+type EvaluatePromptInput struct {
+	Prompt string `json:"prompt" jsonschema:"the prompt to evaluate"`
+	Repo   string `json:"repo,omitempty" jsonschema:"repository whose agents to select from; defaults to the first repository"`
+}
+
+// ListAgentsOutput is the result of the list_agents tool.
+type ListAgentsOutput struct {
+	Repo   string          `json:"repo"`
+	Agents []*agents.Agent `json:"agents"`
+	Count  int             `json:"count"`
+}
+
+// EvaluatePromptOutput is the result of the evaluate_prompt tool.
+type EvaluatePromptOutput struct {
+	OriginalPrompt string                  `json:"original_prompt"`
+	Evaluation     prompt.EvaluationResult `json:"evaluation"`
+	Keywords       []string                `json:"keywords"`
+	SelectedAgents []string                `json:"selected_agents"`
+}
+
+// registerTools registers all orchestrator tools on the MCP server, using the
+// definitions and schemas from Tools.
+func (s *Server) registerTools() {
+	tools := make(map[string]*mcp.Tool)
+	for _, tool := range Tools() {
+		tools[tool.Name] = tool
+	}
+
+	mcp.AddTool(s.mcp, tools["run_with_orchestrator"], s.handleRunWithOrchestrator)
+	mcp.AddTool(s.mcp, tools["list_agents"], s.handleListAgents)
+	mcp.AddTool(s.mcp, tools["run_agent"], s.handleRunAgent)
+	mcp.AddTool(s.mcp, tools["evaluate_prompt"], s.handleEvaluatePrompt)
+	mcp.AddTool(s.mcp, tools["start_orchestration"], s.handleStartOrchestration)
+	mcp.AddTool(s.mcp, tools["get_job_status"], s.handleGetJobStatus)
+	mcp.AddTool(s.mcp, tools["get_job_result"], s.handleGetJobResult)
+	mcp.AddTool(s.mcp, tools["cancel_job"], s.handleCancelJob)
+	mcp.AddTool(s.mcp, tools["list_jobs"], s.handleListJobs)
+	mcp.AddTool(s.mcp, tools["list_repos"], s.handleListRepos)
+	mcp.AddTool(s.mcp, tools["diagnose"], s.handleDiagnose)
+	mcp.AddTool(s.mcp, tools["lint_agents"], s.handleLintAgents)
+}
+
+// handleRunWithOrchestrator runs automatic or explicit orchestration.
+// When the client supplies a progress token, orchestration progress is
+// forwarded as notifications/progress. A cancelled run returns its partial
+// state as an error result.
+func (s *Server) handleRunWithOrchestrator(ctx context.Context, req *mcp.CallToolRequest, in RunWithOrchestratorInput) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Prompt) == "" {
+		return s.toolError(orchestrator.ErrInvalidPrompt)
+	}
+
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	invoker, err := s.agentInvoker(req.Session, repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+	ctx = orchestrator.WithInvoker(ctx, invoker)
+
+	if token := req.Params.GetProgressToken(); token != nil {
+		ctx = orchestrator.WithProgress(ctx, s.progressNotifier(ctx, req.Session, token))
+	}
+
+	s.logger.Info("run_with_orchestrator called",
+		zap.String("repo", repo.Name),
+		zap.String("prompt", in.Prompt),
+		zap.Strings("agents", in.Agents),
+	)
+
+	var state *orchestrator.ContextState
+	if len(in.Agents) > 0 {
+		state, err = repo.Orchestrator.RunWithExplicitChain(ctx, in.Prompt, in.Agents)
+	} else {
+		state, err = repo.Orchestrator.RunWithAuto(ctx, in.Prompt)
+	}
+
+	if state != nil && (state.Status == orchestrator.StatusCancelled || state.Status == orchestrator.StatusTimedOut) {
+		// The request was cancelled or timed out (or the server is shutting
+		// down); return whatever the chain produced before it was interrupted.
+		result, _, encErr := jsonResult(state)
+		if encErr != nil {
+			return nil, nil, encErr
+		}
+		result.IsError = true
+		return result, nil, nil
+	}
+	if err != nil {
+		return s.repoToolError(repo, err)
+	}
+	return jsonResult(state)
+}
+
+// handleListAgents lists all agents discovered in a repository.
+func (s *Server) handleListAgents(_ context.Context, req *mcp.CallToolRequest, in ListAgentsInput) (*mcp.CallToolResult, any, error) {
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	all := repo.Registry.InNamespace(in.Namespace)
+	return jsonResult(ListAgentsOutput{
+		Repo:   repo.Name,
+		Agents: all,
+		Count:  len(all),
+	})
+}
+
+// handleRunAgent invokes a single agent with the configured backend and the
+// execution settings of its frontmatter.
+func (s *Server) handleRunAgent(ctx context.Context, req *mcp.CallToolRequest, in RunAgentInput) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Prompt) == "" {
+		return s.toolError(orchestrator.ErrInvalidPrompt)
+	}
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+	if agent, err := repo.Registry.Lookup(in.AgentName); err != nil {
+		return s.repoToolError(repo, err)
+	} else if agent.Disabled {
+		return s.repoToolError(repo, fmt.Errorf("%w: %s", agents.ErrAgentDisabled, agent.Name))
+	}
+
+	s.logger.Info("run_agent called",
+		zap.String("repo", repo.Name),
+		zap.String("agent", in.AgentName),
+	)
+
+	invoker, err := s.agentInvoker(req.Session, repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	result, err := repo.Orchestrator.InvokeAgent(orchestrator.WithInvoker(ctx, invoker), in.AgentName, in.Prompt)
+	if err != nil {
+		return s.repoToolError(repo, err)
+	}
+	return jsonResult(result)
+}
+
+// handleEvaluatePrompt evaluates a prompt and previews the agent chain
+// run_with_orchestrator would run for it, without running any agent.
+func (s *Server) handleEvaluatePrompt(ctx context.Context, req *mcp.CallToolRequest, in EvaluatePromptInput) (*mcp.CallToolResult, any, error) {
+	repo, err := s.repoFor(req.Session, in.Repo)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	state, err := repo.Orchestrator.Plan(ctx, in.Prompt)
+	if err != nil {
+		return s.toolError(err)
+	}
+
+	return jsonResult(EvaluatePromptOutput{
+		OriginalPrompt: in.Prompt,
+		Evaluation:     state.EvaluationFeedback,
+		Keywords:       prompt.ExtractKeywords(state.RefinedPrompt),
+		SelectedAgents: state.SelectedAgents,
+	})
+}
+
+// progressNotifier returns a ProgressFunc that sends each event to the
+// client as a notifications/progress message for token.
+func (s *Server) progressNotifier(ctx context.Context, session *mcp.ServerSession, token any) orchestrator.ProgressFunc {
+	return func(ev orchestrator.ProgressEvent) {
+		err := session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      float64(ev.Step),
+			Total:         float64(ev.Total),
+			Message:       ev.Message,
+		})
+		if err != nil {
+			s.logger.Debug("failed to send progress notification",
+				zap.String("stage", string(ev.Stage)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// jsonResult returns v as structured content, matching the tool's output
+// schema, along with the same value encoded as indented JSON text content for
+// clients that do not read structured content.
+func jsonResult(v any) (*mcp.CallToolResult, any, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: json.RawMessage(data),
+	}, nil, nil
+}
@@ -0,0 +1,59 @@
+// Package workspace manages the repositories served by a single CopilotOS
+// server.
+//
+// A workspace holds one Repo per repository root. Every repo has its own
+// agent registry, discovered from the repo's .github/agents/ directory, and
+// its own Orchestrator bound to that registry, which runs agents with the
+// repo's root as working directory, so one server connection can
+// orchestrate agents across several services.
+//
+// This package handles:
+//   - Repository Registration: Add and remove repositories by root path
+//   - Per-Repository Discovery: Discover agents separately for each root
+//   - Name Resolution: Look up repositories by name, with a default repo
+//
+// # Repository Names
+//
+// A repo is named after the base name of its root directory, e.g. the root
+// /src/payments is named "payments". Tools address repositories by this
+// name; an empty name selects the default repository, which is the first one
+// added. Two roots with the same base name cannot be served together.
+//
+// # Shared Agents
+//
+// SetSearchPaths adds agent directories searched for every repository after
+// its own .github/agents/, such as the user's agents directory and shared
+// directories of an organization. A repository's agents override shared
+// agents of the same name; the overridden files are kept in Repo.Overrides.
+//
+// Usage Example
+//
+//	ws := workspace.New(invoker, logger)
+//	for _, root := range cfg.RepoRoots {
+//	    if _, err := ws.Add(root); err != nil {
+//	        return err
+//	    }
+//	}
+//
+//	repo, err := ws.Get("payments") // or ws.Get("") for the default repo
+//	if err != nil {
+//	    return err
+//	}
+//	state, err := repo.Orchestrator.RunWithAuto(ctx, prompt)
+//
+// # Hot Reload
+//
+// Watch polls the agent directories of every repository and reloads a
+// repository whose agent files change. The repository's registry receives
+// the rediscovered agents in a single change, which increases its version,
+// and the added, removed and changed agents are logged and passed to a
+// callback; the server uses it to resync its MCP resources and prompts,
+// which notifies clients with list_changed. Chains already running keep the
+// registry snapshot they started with.
+//
+// # Thread Safety
+//
+// The Workspace is safe for concurrent use. Repositories may be added and
+// removed while tool calls are in flight; a Repo obtained from Get stays
+// usable after it has been removed.
+package workspace
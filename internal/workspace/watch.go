@@ -0,0 +1,108 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+// Watch polls the agent directories of every repository each interval and
+// reloads a repository whose agent files were added, removed or modified.
+// Reloading replaces the agents of the repository's registry in one change,
+// and running chains finish with the registry snapshot they started with.
+//
+// When a reload changes the repository's agents, the added, removed and
+// changed agents are logged and onChange is called with the reloaded
+// repository and the diff. Watch blocks until ctx is cancelled.
+func (w *Workspace) Watch(ctx context.Context, interval time.Duration, onChange func(*Repo, agents.Diff)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Fingerprints of the agent files of each repository, by root
+	fingerprints := make(map[string]string)
+	for _, repo := range w.All() {
+		fingerprints[repo.Root] = w.fingerprint(repo.Root)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := make(map[string]string)
+		for _, repo := range w.All() {
+			fp := w.fingerprint(repo.Root)
+			current[repo.Root] = fp
+
+			previous, known := fingerprints[repo.Root]
+			if !known || previous == fp {
+				// Repositories added since the last poll were just discovered.
+				continue
+			}
+
+			reloaded, diff, err := w.reload(repo.Root)
+			if err != nil {
+				// Keep the previous fingerprint, so the next poll retries.
+				current[repo.Root] = previous
+				w.logger.Warn("failed to reload agents", zap.String("repo", repo.Name), zap.Error(err))
+				continue
+			}
+			if diff.Empty() {
+				continue
+			}
+			w.logger.Info("agents reloaded",
+				zap.String("repo", reloaded.Name),
+				zap.Strings("added", diff.Added),
+				zap.Strings("removed", diff.Removed),
+				zap.Strings("changed", diff.Changed),
+			)
+			if onChange != nil {
+				onChange(reloaded, diff)
+			}
+		}
+		fingerprints = current
+	}
+}
+
+// fingerprint summarizes the name, size and modification time of every
+// agent file that discovery would read for the repository at root, so any
+// edit changes it.
+func (w *Workspace) fingerprint(root string) string {
+	w.mu.RLock()
+	var dirs []string
+	for _, path := range append(agents.RepoSearchPaths(root), w.searchPaths...) {
+		dirs = append(dirs, path.Dir)
+	}
+	w.mu.RUnlock()
+
+	var b strings.Builder
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if path != dir && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(entry.Name(), ".md") {
+				return nil
+			}
+			if info, err := entry.Info(); err == nil {
+				fmt.Fprintf(&b, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+			}
+			return nil
+		})
+	}
+	return b.String()
+}
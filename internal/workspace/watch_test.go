@@ -0,0 +1,135 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+func TestWorkspace_Watch(t *testing.T) {
+	root := writeRepo(t, t.TempDir(), "payments", "code-reviewer")
+	agentsDir := agents.AgentsDir(root)
+
+	ws := New(nil, zap.NewNop())
+	repo, err := ws.Add(root)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	before := repo.Registry.Snapshot()
+
+	type change struct {
+		repo *Repo
+		diff agents.Diff
+	}
+	changes := make(chan change, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ws.Watch(ctx, 10*time.Millisecond, func(repo *Repo, diff agents.Diff) {
+		changes <- change{repo, diff}
+	})
+
+	// Let the watcher take its first fingerprint before editing.
+	time.Sleep(50 * time.Millisecond)
+	edited := "---\nname: code-reviewer\ndescription: Reviews code for security\nkeywords: [security]\n---\n"
+	if err := os.WriteFile(filepath.Join(agentsDir, "code-reviewer.md"), []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added := "---\nname: test-generator\nkeywords: [testing]\n---\n"
+	if err := os.WriteFile(filepath.Join(agentsDir, "test-generator.md"), []byte(added), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got change
+	select {
+	case got = <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	// Both edits may land in one poll or in two; wait for the second if needed.
+	diff := got.diff
+	if len(diff.Added) == 0 || len(diff.Changed) == 0 {
+		select {
+		case next := <-changes:
+			got = next
+			diff.Added = append(diff.Added, next.diff.Added...)
+			diff.Changed = append(diff.Changed, next.diff.Changed...)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the second reload, got %+v", diff)
+		}
+	}
+	want := agents.Diff{Added: []string{"test-generator"}, Changed: []string{"code-reviewer"}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected diff %+v, got %+v", want, diff)
+	}
+
+	current, err := ws.Get("payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != got.repo || current.Registry.Get("test-generator") == nil {
+		t.Errorf("expected the reloaded repository to be served")
+	}
+	// Runs holding the previous snapshot keep its agents.
+	if before.Get("test-generator") != nil || before.Get("code-reviewer").Description != "Test agent" {
+		t.Errorf("expected the previous snapshot to be unchanged")
+	}
+}
+
+func TestWorkspace_Watch_RetriesFailedReload(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read directories without permissions")
+	}
+	root := writeRepo(t, t.TempDir(), "payments", "code-reviewer")
+	agentsDir := agents.AgentsDir(root)
+
+	ws := New(nil, zap.NewNop())
+	if _, err := ws.Add(root); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	changes := make(chan agents.Diff, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ws.Watch(ctx, 10*time.Millisecond, func(_ *Repo, diff agents.Diff) {
+		changes <- diff
+	})
+
+	// Edit an agent while an unreadable directory makes the reload fail.
+	time.Sleep(50 * time.Millisecond)
+	locked := filepath.Join(agentsDir, "security")
+	if err := os.Mkdir(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+	edited := "---\nname: code-reviewer\ndescription: Reviews code for security\nkeywords: [security]\n---\n"
+	if err := os.WriteFile(filepath.Join(agentsDir, "code-reviewer.md"), []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case diff := <-changes:
+		t.Fatalf("expected the reload to fail, got %+v", diff)
+	default:
+	}
+
+	// Fixing the directory leaves the agent files as they were; the failed
+	// reload is retried all the same.
+	if err := os.Chmod(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case diff := <-changes:
+		if want := (agents.Diff{Changed: []string{"code-reviewer"}}); !reflect.DeepEqual(diff, want) {
+			t.Errorf("expected diff %+v, got %+v", want, diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload to be retried")
+	}
+}
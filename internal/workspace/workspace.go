@@ -0,0 +1,367 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"github.com/rayprogramming/copilot-os/internal/orchestrator"
+	"github.com/rayprogramming/copilot-os/internal/prompt"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrRepoNotFound is returned when a repository name is not served.
+	ErrRepoNotFound = errors.New("repository not found")
+
+	// ErrNoRepos is returned when the default repository is requested from
+	// an empty workspace.
+	ErrNoRepos = errors.New("no repositories configured")
+)
+
+// Repo is a repository served by the workspace.
+type Repo struct {
+	Name         string
+	Root         string
+	Registry     *agents.Registry
+	Orchestrator *orchestrator.Orchestrator
+	Failures     []agents.ParseFailure // Agent files discovery skipped
+	Overrides    []agents.Override     // Agent files overridden by a search path of higher precedence
+}
+
+// Info describes a repository for clients.
+type Info struct {
+	Name       string `json:"name"`
+	Root       string `json:"root"`
+	AgentCount int    `json:"agent_count"`
+	Default    bool   `json:"default"`
+}
+
+// Workspace holds the repositories served by one server.
+type Workspace struct {
+	invoker     orchestrator.Invoker
+	logger      *zap.Logger
+	searchPaths []agents.SearchPath // Searched after each repository's agents directory
+
+	mu         sync.RWMutex
+	repos      map[string]*Repo
+	order      []string // Names in the order they were added; the first is the default
+	configured []*Repo  // Repositories added with Add or AddRegistry, served or not
+}
+
+// New creates an empty workspace whose orchestrators invoke agents with invoker.
+func New(invoker orchestrator.Invoker, logger *zap.Logger) *Workspace {
+	return &Workspace{
+		invoker: invoker,
+		logger:  logger,
+		repos:   make(map[string]*Repo),
+	}
+}
+
+// SetSearchPaths sets the directories searched for agents after each
+// repository's own agents directory, in order of decreasing precedence. It
+// applies to repositories discovered afterwards.
+func (w *Workspace) SetSearchPaths(paths []agents.SearchPath) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.searchPaths = paths
+}
+
+// Add discovers the agents under root and adds it as a repository.
+func (w *Workspace) Add(root string) (*Repo, error) {
+	abs, discovery, err := w.discover(root)
+	if err != nil {
+		return nil, err
+	}
+	repo := w.newRepo(filepath.Base(abs), abs, discovery.Registry())
+	repo.Failures = discovery.Failures()
+	repo.Overrides = discovery.Overrides()
+	if err := w.add(repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// discover resolves root to an absolute path and discovers its agents.
+func (w *Workspace) discover(root string) (string, *agents.Discovery, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid repository root %q: %w", root, err)
+	}
+
+	w.mu.RLock()
+	searchPaths := w.searchPaths
+	w.mu.RUnlock()
+
+	discovery := agents.NewDiscovery(abs, w.logger)
+	discovery.SetSearchPaths(searchPaths)
+	if err := discovery.Discover(); err != nil {
+		return "", nil, fmt.Errorf("agent discovery failed for %s: %w", abs, err)
+	}
+	return abs, discovery, nil
+}
+
+// newRepo creates a repository with its own orchestrator, which runs agents
+// in root.
+func (w *Workspace) newRepo(name, root string, registry *agents.Registry) *Repo {
+	orch := orchestrator.NewOrchestrator(registry, w.invoker, w.logger.With(zap.String("repo", name)))
+	orch.SetDir(root)
+	return &Repo{
+		Name:         name,
+		Root:         root,
+		Registry:     registry,
+		Orchestrator: orch,
+	}
+}
+
+// Sync makes the workspace serve exactly roots, in order, so the first root
+// becomes the default repository. Repositories that are already served, or
+// that were added with Add or AddRegistry, keep their registry; new roots are
+// discovered. A root that cannot be discovered,
+// or whose name is already taken by an earlier root, is skipped with a
+// warning. Sync reports whether the set or order of repositories changed.
+func (w *Workspace) Sync(roots []string) bool {
+	w.mu.RLock()
+	byRoot := make(map[string]*Repo, len(w.repos)+len(w.configured))
+	for _, repo := range w.configured {
+		byRoot[repo.Root] = repo
+	}
+	for _, repo := range w.repos {
+		byRoot[repo.Root] = repo
+	}
+	previous := append([]string(nil), w.order...)
+	w.mu.RUnlock()
+
+	repos := make(map[string]*Repo, len(roots))
+	var order []string
+	for _, root := range roots {
+		repo := byRoot[root]
+		if repo == nil {
+			if abs, err := filepath.Abs(root); err == nil {
+				repo = byRoot[abs]
+			}
+		}
+		if repo == nil {
+			abs, discovery, err := w.discover(root)
+			if err != nil {
+				w.logger.Warn("skipping repository", zap.String("root", root), zap.Error(err))
+				continue
+			}
+			if existing := byRoot[abs]; existing != nil {
+				repo = existing
+			} else {
+				repo = w.newRepo(filepath.Base(abs), abs, discovery.Registry())
+				repo.Failures = discovery.Failures()
+				repo.Overrides = discovery.Overrides()
+			}
+		}
+
+		if existing, ok := repos[repo.Name]; ok {
+			if existing.Root != repo.Root {
+				w.logger.Warn("skipping repository with duplicate name",
+					zap.String("repo", repo.Name),
+					zap.String("root", repo.Root),
+					zap.String("existing_root", existing.Root),
+				)
+			}
+			continue
+		}
+		repos[repo.Name] = repo
+		order = append(order, repo.Name)
+	}
+
+	w.mu.Lock()
+	w.repos = repos
+	w.order = order
+	w.mu.Unlock()
+
+	changed := !slices.Equal(previous, order)
+	if changed {
+		w.logger.Info("repositories updated", zap.Strings("repos", order))
+	}
+	return changed
+}
+
+// Reload rediscovers the agents of the repository served from root and
+// replaces the agents of its registry in a single change, so the registry's
+// version increases. Runs already in progress keep the registry snapshot
+// they started with.
+func (w *Workspace) Reload(root string) error {
+	_, _, err := w.reload(root)
+	return err
+}
+
+// reload is Reload, returning the reloaded repository and how its agents
+// changed.
+func (w *Workspace) reload(root string) (*Repo, agents.Diff, error) {
+	abs, discovery, err := w.discover(root)
+	if err != nil {
+		return nil, agents.Diff{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for name, repo := range w.repos {
+		if repo.Root == abs {
+			previous := repo.Registry.Snapshot()
+			discovered := discovery.Registry().Snapshot()
+			diff := agents.DiffSnapshots(previous, discovered)
+			if !diff.Empty() {
+				if err := repo.Registry.ReplaceAll(discovered.All()); err != nil {
+					return nil, agents.Diff{}, fmt.Errorf("failed to reload agents of %s: %w", name, err)
+				}
+			}
+
+			reloaded := *repo
+			reloaded.Failures = discovery.Failures()
+			reloaded.Overrides = discovery.Overrides()
+			w.repos[name] = &reloaded
+			if i := slices.Index(w.configured, repo); i >= 0 {
+				w.configured[i] = &reloaded
+			}
+			w.logger.Info("repository reloaded",
+				zap.String("repo", name),
+				zap.Int("agents", len(discovered.All())),
+				zap.Uint64("registry_version", reloaded.Registry.Version()),
+			)
+			return &reloaded, diff, nil
+		}
+	}
+	return nil, agents.Diff{}, fmt.Errorf("%w: no repository at %s", ErrRepoNotFound, abs)
+}
+
+// Lint checks the agent files of repo and of the workspace's search paths
+// against the keywords prompts produce; see agents.Discovery.Lint. The
+// served registry is not changed.
+func (w *Workspace) Lint(repo *Repo) (*agents.LintReport, error) {
+	w.mu.RLock()
+	searchPaths := w.searchPaths
+	w.mu.RUnlock()
+
+	discovery := agents.NewDiscovery(repo.Root, w.logger)
+	discovery.SetSearchPaths(searchPaths)
+	report, err := discovery.Lint(prompt.Keywords())
+	if err != nil {
+		return nil, fmt.Errorf("failed to lint agents of %s: %w", repo.Name, err)
+	}
+	return report, nil
+}
+
+// AddRegistry adds a repository with an already populated registry.
+func (w *Workspace) AddRegistry(name, root string, registry *agents.Registry) (*Repo, error) {
+	repo := w.newRepo(name, root, registry)
+	if err := w.add(repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// add publishes repo, which must be complete: readers may use it as soon as
+// add takes the lock.
+func (w *Workspace) add(repo *Repo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if existing, ok := w.repos[repo.Name]; ok {
+		return fmt.Errorf("repository name %q already used by %s", repo.Name, existing.Root)
+	}
+
+	w.repos[repo.Name] = repo
+	w.order = append(w.order, repo.Name)
+	w.configured = append(w.configured, repo)
+
+	w.logger.Info("repository added",
+		zap.String("repo", repo.Name),
+		zap.String("root", repo.Root),
+		zap.Int("agents", len(repo.Registry.All())),
+	)
+	return nil
+}
+
+// Remove stops serving the named repository, which Sync then no longer
+// counts as configured. It reports whether the repository was present.
+func (w *Workspace) Remove(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	repo, ok := w.repos[name]
+	if !ok {
+		return false
+	}
+	w.configured = slices.DeleteFunc(w.configured, func(r *Repo) bool { return r.Root == repo.Root })
+	delete(w.repos, name)
+	for i, n := range w.order {
+		if n == name {
+			w.order = append(w.order[:i], w.order[i+1:]...)
+			break
+		}
+	}
+
+	w.logger.Info("repository removed", zap.String("repo", name))
+	return true
+}
+
+// ConfiguredRoots returns the roots of the repositories added with Add or
+// AddRegistry and not removed, in the order they were added, whether Sync
+// currently serves them or not.
+func (w *Workspace) ConfiguredRoots() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	roots := make([]string, len(w.configured))
+	for i, repo := range w.configured {
+		roots[i] = repo.Root
+	}
+	return roots
+}
+
+// Get returns the named repository, or the default repository when name is
+// empty.
+func (w *Workspace) Get(name string) (*Repo, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if name == "" {
+		if len(w.order) == 0 {
+			return nil, ErrNoRepos
+		}
+		return w.repos[w.order[0]], nil
+	}
+
+	repo, ok := w.repos[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrRepoNotFound, name)
+	}
+	return repo, nil
+}
+
+// All returns every repository, the default first.
+func (w *Workspace) All() []*Repo {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	repos := make([]*Repo, len(w.order))
+	for i, name := range w.order {
+		repos[i] = w.repos[name]
+	}
+	return repos
+}
+
+// Infos describes every repository, the default first.
+func (w *Workspace) Infos() []Info {
+	repos := w.All()
+	infos := make([]Info, len(repos))
+	for i, repo := range repos {
+		infos[i] = Info{
+			Name:       repo.Name,
+			Root:       repo.Root,
+			AgentCount: len(repo.Registry.All()),
+			Default:    i == 0,
+		}
+	}
+	return infos
+}
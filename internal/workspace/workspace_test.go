@@ -0,0 +1,205 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rayprogramming/copilot-os/internal/agents"
+	"go.uber.org/zap"
+)
+
+// writeRepo creates a repository root named name containing one agent.
+func writeRepo(t *testing.T, parent, name, agent string) string {
+	t.Helper()
+
+	root := filepath.Join(parent, name)
+	dir := filepath.Join(root, ".github", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create agents dir: %v", err)
+	}
+	content := "---\nname: " + agent + "\ndescription: Test agent\nkeywords: [test]\n---\n"
+	if err := os.WriteFile(filepath.Join(dir, agent+".md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write agent: %v", err)
+	}
+	return root
+}
+
+func TestWorkspace_Add(t *testing.T) {
+	parent := t.TempDir()
+	ws := New(nil, zap.NewNop())
+
+	for _, repo := range []struct{ name, agent string }{
+		{"payments", "code-reviewer"},
+		{"search", "test-generator"},
+	} {
+		if _, err := ws.Add(writeRepo(t, parent, repo.name, repo.agent)); err != nil {
+			t.Fatalf("Add(%s) failed: %v", repo.name, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		repo      string
+		wantRepo  string
+		wantAgent string
+	}{
+		{"default repo", "", "payments", "code-reviewer"},
+		{"named repo", "search", "search", "test-generator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := ws.Get(tt.repo)
+			if err != nil {
+				t.Fatalf("Get(%q) failed: %v", tt.repo, err)
+			}
+			if repo.Name != tt.wantRepo {
+				t.Errorf("expected repo %q, got %q", tt.wantRepo, repo.Name)
+			}
+			if repo.Registry.Get(tt.wantAgent) == nil {
+				t.Errorf("expected agent %q in %s", tt.wantAgent, repo.Name)
+			}
+			if repo.Orchestrator == nil {
+				t.Error("expected an orchestrator per repository")
+			}
+		})
+	}
+
+	infos := ws.Infos()
+	if len(infos) != 2 || !infos[0].Default || infos[1].Default || infos[1].AgentCount != 1 {
+		t.Errorf("unexpected repository infos: %+v", infos)
+	}
+}
+
+func TestWorkspace_SearchPaths(t *testing.T) {
+	parent := t.TempDir()
+	shared := filepath.Join(writeRepo(t, parent, "org", "code-reviewer"), ".github", "agents")
+
+	ws := New(nil, zap.NewNop())
+	ws.SetSearchPaths([]agents.SearchPath{{Dir: shared, Source: agents.SourceShared}})
+	repo, err := ws.Add(writeRepo(t, parent, "payments", "code-reviewer"))
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if agent := repo.Registry.Get("code-reviewer"); agent == nil || agent.Source != agents.SourceRepo {
+		t.Errorf("expected the repository's code-reviewer, got %+v", agent)
+	}
+	if len(repo.Overrides) != 1 || repo.Overrides[0].Name != "code-reviewer" {
+		t.Errorf("expected the shared code-reviewer to be overridden, got %+v", repo.Overrides)
+	}
+
+	// Lint checks the shared files too; keyword test is never extracted
+	// from a prompt.
+	report, err := ws.Lint(repo)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if report.Files != 2 || report.Errors != 0 || report.Warnings != 2 {
+		t.Errorf("expected 2 files with 2 warnings, got %+v", report)
+	}
+}
+
+func TestWorkspace_Errors(t *testing.T) {
+	ws := New(nil, zap.NewNop())
+
+	if _, err := ws.Get(""); !errors.Is(err, ErrNoRepos) {
+		t.Errorf("expected ErrNoRepos, got %v", err)
+	}
+
+	ws.AddRegistry("payments", "/src/payments", agents.NewRegistry())
+	if _, err := ws.AddRegistry("payments", "/other/payments", agents.NewRegistry()); err == nil {
+		t.Error("expected error for duplicate repository name")
+	}
+	if _, err := ws.Get("missing"); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("expected ErrRepoNotFound, got %v", err)
+	}
+
+	if !ws.Remove("payments") || ws.Remove("payments") {
+		t.Error("expected Remove to report presence once")
+	}
+	if len(ws.All()) != 0 {
+		t.Errorf("expected empty workspace, got %d repos", len(ws.All()))
+	}
+}
+
+func TestWorkspace_Sync(t *testing.T) {
+	parent := t.TempDir()
+	payments := writeRepo(t, parent, "payments", "code-reviewer")
+	search := writeRepo(t, parent, "search", "test-generator")
+	other := writeRepo(t, t.TempDir(), "search", "doc-writer")
+
+	ws := New(nil, zap.NewNop())
+	original, err := ws.Add(payments)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if !ws.Sync([]string{search, payments, other}) {
+		t.Error("expected Sync to report a change")
+	}
+	names := make([]string, 0)
+	for _, repo := range ws.All() {
+		names = append(names, repo.Name)
+	}
+	if len(names) != 2 || names[0] != "search" || names[1] != "payments" {
+		t.Errorf("expected [search payments] with the duplicate name skipped, got %v", names)
+	}
+	if repo, _ := ws.Get("payments"); repo != original {
+		t.Error("expected an already served repository to be kept")
+	}
+
+	if ws.Sync([]string{search, payments}) {
+		t.Error("expected Sync without changes to report none")
+	}
+	if !ws.Sync([]string{payments}) || len(ws.All()) != 1 {
+		t.Errorf("expected search to be removed, got %d repos", len(ws.All()))
+	}
+
+	// Repositories added with Add stay configured when Sync drops them.
+	ws.Sync([]string{search})
+	if roots := ws.ConfiguredRoots(); len(roots) != 1 || roots[0] != original.Root {
+		t.Errorf("expected configured roots [%s], got %v", original.Root, roots)
+	}
+	ws.Sync(ws.ConfiguredRoots())
+	if repo, _ := ws.Get("payments"); repo != original {
+		t.Error("expected the configured repository to be served again with its registry")
+	}
+	ws.Remove("payments")
+	if roots := ws.ConfiguredRoots(); len(roots) != 0 {
+		t.Errorf("expected Remove to drop the configured root, got %v", roots)
+	}
+}
+
+func TestWorkspace_Reload(t *testing.T) {
+	parent := t.TempDir()
+	root := writeRepo(t, parent, "payments", "code-reviewer")
+
+	ws := New(nil, zap.NewNop())
+	before, err := ws.Add(root)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	snapshot := before.Registry.Snapshot()
+	writeRepo(t, parent, "payments", "test-generator")
+
+	if err := ws.Reload(root); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	after, _ := ws.Get("payments")
+	if after.Registry.Get("test-generator") == nil {
+		t.Error("expected Reload to rediscover the repository's agents")
+	}
+	if after.Registry.Version() <= snapshot.Version() {
+		t.Errorf("expected the registry version to increase from %d, got %d", snapshot.Version(), after.Registry.Version())
+	}
+	if snapshot.Get("test-generator") != nil {
+		t.Error("expected the previous snapshot to be left untouched")
+	}
+
+	if err := ws.Reload(t.TempDir()); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("expected ErrRepoNotFound for an unserved root, got %v", err)
+	}
+}